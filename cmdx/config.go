@@ -0,0 +1,113 @@
+package cmdx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/odpf/salt/config"
+	"github.com/spf13/cobra"
+)
+
+const defaultConfigFile = "config.yaml"
+
+// SetConfig adds a config command to cmd with subcommands to initialize,
+// view and validate the config which is loaded into cfg by loader,
+// cfg must be a pointer to the config struct of the binary
+func SetConfig(cmd *cobra.Command, loader *config.Loader, cfg interface{}) {
+	configCmd := &cobra.Command{
+		Use:   "config <command>",
+		Short: "Manage config",
+		Annotations: map[string]string{
+			"group:other": "config",
+		},
+	}
+	configCmd.AddCommand(configInitCommand(loader, cfg))
+	configCmd.AddCommand(configViewCommand(loader, cfg))
+	configCmd.AddCommand(configValidateCommand(loader, cfg))
+	cmd.AddCommand(configCmd)
+}
+
+func configInitCommand(loader *config.Loader, cfg interface{}) *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "init [path]",
+		Short: "Write a sample config file with the default values",
+		Long: "Write a sample config file with the default values to the given path, " +
+			"the config file of the command or " + defaultConfigFile + ". " +
+			"The file is written as json if the path has a .json extension, as yaml otherwise.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := loader.ConfigFileUsed()
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if path == "" {
+				path = defaultConfigFile
+			}
+
+			if _, err := os.Stat(path); err == nil && !force {
+				return fmt.Errorf("config file %s already exists, use --force to overwrite it", path)
+			}
+
+			format := "yaml"
+			if filepath.Ext(path) == ".json" {
+				format = "json"
+			}
+			sample, err := loader.GenerateSample(cfg, format)
+			if err != nil {
+				return err
+			}
+
+			// the file is private to the user as it is going to hold secrets
+			if err := ioutil.WriteFile(path, sample, 0600); err != nil {
+				return fmt.Errorf("unable to write config file: %v", err)
+			}
+			cmd.Printf("config file written to %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite the config file if it exists")
+	return cmd
+}
+
+func configViewCommand(loader *config.Loader, cfg interface{}) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective config with secrets masked",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := loader.Load(cfg); err != nil {
+				return err
+			}
+
+			printable, err := config.GetPrintable(cfg, config.WithPrintFormat(format))
+			if err != nil {
+				return err
+			}
+			cmd.Println(printable)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&format, "format", "o", "yaml", "Output format, json or yaml")
+	return cmd
+}
+
+func configValidateCommand(loader *config.Loader, cfg interface{}) *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the config",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// the error is returned as is to keep it usable with errors.As
+			// e.g. for a *config.ValidationError
+			if err := loader.Load(cfg); err != nil {
+				return err
+			}
+			cmd.Println("config is valid")
+			return nil
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package cmdx_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/odpf/salt/cmdx"
+	"github.com/odpf/salt/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+type appConfig struct {
+	Host     string              `mapstructure:"host" default:"localhost"`
+	DSN      string              `mapstructure:"dsn" required:"true"`
+	Password config.SecretString `mapstructure:"password"`
+}
+
+// execute runs the config command with the given args and returns its
+// combined output
+func execute(loader *config.Loader, cfg interface{}, args ...string) (string, error) {
+	root := &cobra.Command{Use: "app"}
+	cmdx.SetConfig(root, loader, cfg)
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(append([]string{"config"}, args...))
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestConfigInit(t *testing.T) {
+	t.Run("should write sample config file private to the user", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+
+		out, err := execute(config.NewLoader(), &appConfig{}, "init", path)
+		assert.NoError(t, err)
+		assert.Equal(t, "config file written to "+path+"\n", out)
+
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		content, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "host: localhost\n")
+	})
+
+	t.Run("should not overwrite existing file without force", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := ioutil.WriteFile(path, []byte("host: existing\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := execute(config.NewLoader(), &appConfig{}, "init", path)
+		assert.EqualError(t, err, "config file "+path+" already exists, use --force to overwrite it")
+		content, _ := ioutil.ReadFile(path)
+		assert.Equal(t, "host: existing\n", string(content))
+
+		_, err = execute(config.NewLoader(), &appConfig{}, "init", path, "--force")
+		assert.NoError(t, err)
+		content, _ = ioutil.ReadFile(path)
+		assert.Contains(t, string(content), "host: localhost\n")
+	})
+}
+
+func TestConfigView(t *testing.T) {
+	t.Run("should print config with secrets masked", func(t *testing.T) {
+		dir := t.TempDir()
+		content := "dsn: postgres://localhost\npassword: s3cr3t\n"
+		if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := execute(config.NewLoader(config.WithPath(dir)), &appConfig{}, "view")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "postgres://localhost")
+		assert.Contains(t, out, "****************")
+		assert.NotContains(t, out, "s3cr3t")
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("should return and print validation error", func(t *testing.T) {
+		out, err := execute(config.NewLoader(config.WithPath(t.TempDir())), &appConfig{}, "validate")
+
+		var verr *config.ValidationError
+		if assert.True(t, errors.As(err, &verr)) {
+			assert.Equal(t, []config.FieldError{
+				{Key: "dsn", Rule: "required", Message: "required field is not set"},
+			}, verr.Errors)
+		}
+		assert.Contains(t, out, "Error: invalid config: dsn: required field is not set\n")
+		assert.NotContains(t, out, "config is valid")
+	})
+
+	t.Run("should print valid config", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte("dsn: postgres://localhost\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := execute(config.NewLoader(config.WithPath(dir)), &appConfig{}, "validate")
+		assert.NoError(t, err)
+		assert.Equal(t, "config is valid\n", out)
+	})
+}
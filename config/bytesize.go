@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes which can be set in human readable form
+// e.g. `512MB` or `1.5GiB`, units are powers of 1024 and case
+// insensitive. Plain numbers are loaded as bytes.
+type ByteSize int64
+
+const (
+	_           = iota
+	KB ByteSize = 1 << (10 * iota)
+	MB
+	GB
+	TB
+)
+
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+var byteSizeUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"k":   KB,
+	"kb":  KB,
+	"kib": KB,
+	"m":   MB,
+	"mb":  MB,
+	"mib": MB,
+	"g":   GB,
+	"gb":  GB,
+	"gib": GB,
+	"t":   TB,
+	"tb":  TB,
+	"tib": TB,
+}
+
+// ParseByteSize parses a human readable size e.g. `512MB` into bytes
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	num, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	unit, ok := byteSizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid unit in byte size %q", s)
+	}
+	return ByteSize(num * float64(unit)), nil
+}
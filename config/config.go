@@ -1,18 +1,71 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/jeremywohl/flatten"
 	"github.com/mcuadros/go-defaults"
 	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/odpf/salt/log"
 )
 
 type Loader struct {
-	v *viper.Viper
+	v               *viper.Viper
+	configFile      string
+	configType      string
+	configBytes     []byte
+	mergeFiles      []string
+	overlayEnv      string
+	remoteProviders []remoteProvider
+	remoteProvider  *remoteProvider
+	remoteKeys      map[string]bool
+	mergedKeys      map[string]string
+	flags           map[string]*pflag.Flag
+	decryptor       Decryptor
+
+	dotEnvFiles      []string
+	envPrefix        string
+	envKeyReplacer   *strings.Replacer
+	flattenStyle     flatten.SeparatorStyle
+	readTimeout      time.Duration
+	kebabKeys        bool
+	migrations       []Migration
+	envInterpolation bool
+	envOptIn         bool
+	indexedEnv       bool
+	indexedEnvGaps   bool
+	envTypeCheck     bool
+
+	flexibleStructs map[reflect.Type]string
+	validator       StructValidator
+	strict          bool
+	decodeHooks     []mapstructure.DecodeHookFunc
+
+	secretResolver        SecretResolver
+	schemeResolvers       map[string]SecretResolver
+	forbidDefaultSecrets  bool
+	revealSecrets         bool
+	secureFilePermissions bool
+
+	reportMissingEnv bool
+	missingEnv       []string
+
+	retainRawConfig bool
+	rawConfig       []byte
+
+	summaryLogger log.Logger
 }
 
 type LoaderOption func(*Loader)
@@ -26,10 +79,60 @@ func WithViper(in *viper.Viper) LoaderOption {
 }
 
 // WithFile explicitly defines the path, name and extension
-// of the config file
+// of the config file, same as WithConfigFile
 func WithFile(file string) LoaderOption {
+	return WithConfigFile(file)
+}
+
+// WithConfigFile loads the config from the given file path e.g. one set
+// with a `--config` flag, the config type is inferred from the extension.
+// Load returns an error if the file does not exist. The file takes
+// precedence over the name and paths set with WithName and WithPath.
+func WithConfigFile(path string) LoaderOption {
+	return func(l *Loader) {
+		l.configFile = path
+		l.v.SetConfigFile(path)
+		ext := strings.TrimPrefix(filepath.Ext(path), ".")
+		for _, supported := range viper.SupportedExts {
+			if ext == supported {
+				l.v.SetConfigType(ext)
+			}
+		}
+	}
+}
+
+// WithMergeFile merges the given file over the config file, only the keys
+// set in it override the ones in the config file. Can be used multiple
+// times, later files override the earlier ones. Files which do not exist
+// are skipped. Env variables still override the values of all the files.
+func WithMergeFile(path string) LoaderOption {
 	return func(l *Loader) {
-		l.v.SetConfigFile(file)
+		l.mergeFiles = append(l.mergeFiles, path)
+	}
+}
+
+// WithFiles loads the config from the first file, same as WithConfigFile,
+// and merges the rest over it in order, same as WithMergeFile
+func WithFiles(paths ...string) LoaderOption {
+	return func(l *Loader) {
+		if len(paths) == 0 {
+			return
+		}
+		WithConfigFile(paths[0])(l)
+		for _, path := range paths[1:] {
+			WithMergeFile(path)(l)
+		}
+	}
+}
+
+// WithOverlayFromEnv merges the overlay for the environment named by the
+// given env variable over the config file e.g. with APP_ENV=production
+// config.production.yaml is merged over config.yaml. The overlay is
+// skipped if the env variable is not set or the file does not exist and
+// is merged before the files added with WithMergeFile.
+func WithOverlayFromEnv(env string) LoaderOption {
+	return func(l *Loader) {
+		l.overlayEnv = env
 	}
 }
 
@@ -54,32 +157,73 @@ func WithPath(in string) LoaderOption {
 // Also used for the extension of the file
 func WithType(in string) LoaderOption {
 	return func(l *Loader) {
+		l.configType = in
 		l.v.SetConfigType(in)
 	}
 }
 
+// WithConfigBytes loads the config from the given content instead of a
+// config file e.g. one embedded with go:embed, parsed as per the type set
+// with WithType (yaml by default). Env variables and defaults are applied
+// the same as for config files.
+func WithConfigBytes(data []byte) LoaderOption {
+	return func(l *Loader) {
+		l.configBytes = data
+	}
+}
+
 // WithEnvPrefix sets the prefix for keys when checking for configs
 // in environment variables. Internally concatenates with keys
 // with `_` in between
 func WithEnvPrefix(in string) LoaderOption {
 	return func(l *Loader) {
+		l.envPrefix = in
 		l.v.SetEnvPrefix(in)
 	}
 }
 
+// WithKebabKeys maps kebab-case keys to fields without a mapstructure
+// tag e.g. `read-timeout` is loaded into the ReadTimeout field
+func WithKebabKeys() LoaderOption {
+	return func(l *Loader) {
+		l.kebabKeys = true
+	}
+}
+
 // WithEnvKeyReplacer sets the `old` string to be replaced with
 // the `new` string environmental variable to a key that does
 // not match it.
 func WithEnvKeyReplacer(old string, new string) LoaderOption {
 	return func(l *Loader) {
-		l.v.SetEnvKeyReplacer(strings.NewReplacer(old, new))
+		l.envKeyReplacer = strings.NewReplacer(old, new)
+		l.v.SetEnvKeyReplacer(l.envKeyReplacer)
+	}
+}
+
+// WithRawConfig retains the raw content of the config file read
+// during Load, see Loader.RawConfig
+func WithRawConfig() LoaderOption {
+	return func(l *Loader) {
+		l.retainRawConfig = true
+	}
+}
+
+// WithReadTimeout sets the max duration for reading the config file,
+// so that Load does not block forever on unresponsive network file
+// systems. On timeout the read is abandoned and Load returns an error,
+// the loader must not be used after that as the read may still complete.
+func WithReadTimeout(d time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.readTimeout = d
 	}
 }
 
 // NewLoader returns a config loader with given LoaderOption(s)
 func NewLoader(options ...LoaderOption) *Loader {
 	loader := &Loader{
-		v: getViperWithDefaults(),
+		v:              getViperWithDefaults(),
+		envKeyReplacer: defaultEnvKeyReplacer,
+		flattenStyle:   flatten.DotStyle,
 	}
 
 	for _, option := range options {
@@ -95,35 +239,384 @@ func (l *Loader) Load(config interface{}) error {
 		return err
 	}
 
-	l.v.AutomaticEnv()
+	if len(l.dotEnvFiles) > 0 {
+		if err := l.loadDotEnv(); err != nil {
+			return err
+		}
+	}
+
+	if err := l.readInConfig(); err != nil {
+		return err
+	}
 
-	if err := l.v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return fmt.Errorf("unable to read configs using viper: %v", err)
+	if len(l.remoteProviders) > 0 {
+		if err := l.readRemoteConfig(); err != nil {
+			return err
 		}
 	}
 
-	configKeys, err := getFlattenedStructKeys(config)
-	if err != nil {
-		return fmt.Errorf("unable to get all config keys from struct: %v", err)
+	if len(l.migrations) > 0 || l.envInterpolation || len(l.mergeFiles) > 0 || l.overlayEnv != "" {
+		if err := l.transformConfig(); err != nil {
+			return err
+		}
+	}
+
+	if len(l.flags) > 0 {
+		if err := l.bindFlags(); err != nil {
+			return err
+		}
 	}
 
 	// Bind each conf fields from struct to environment vars
-	for key := range configKeys {
-		if err := l.v.BindEnv(configKeys[key]); err != nil {
-			return fmt.Errorf("unable to bind env keys: %v", err)
+	envKeys, err := l.bindEnvKeys(config, l.getConfigKeys(config))
+	if err != nil {
+		return fmt.Errorf("unable to bind env keys: %v", err)
+	}
+
+	if l.envTypeCheck {
+		if err := l.checkEnvTypes(config, envKeys); err != nil {
+			return err
 		}
 	}
 
+	if l.reportMissingEnv {
+		l.missingEnv = l.getMissingEnv(envKeys)
+	}
+
 	// set defaults using the default struct tag
 	defaults.SetDefaults(config)
+	if err := l.setHookedDefaults(reflect.ValueOf(config).Elem()); err != nil {
+		return fmt.Errorf("unable to set defaults: %v", err)
+	}
+
+	var metadata mapstructure.Metadata
+	if err := l.v.Unmarshal(config, viper.DecodeHook(l.decodeHook()), func(c *mapstructure.DecoderConfig) {
+		c.Metadata = &metadata
+	}); err != nil {
+		return fmt.Errorf("unable to load config to struct: %v", err)
+	}
+
+	if l.indexedEnv {
+		if err := l.loadIndexedEnv(config); err != nil {
+			return err
+		}
+	}
+
+	if l.secureFilePermissions {
+		if err := l.checkFilePermissions(config); err != nil {
+			return err
+		}
+	}
+
+	if l.secretResolver != nil || len(l.schemeResolvers) > 0 {
+		if err := l.resolveSecrets(context.Background(), config); err != nil {
+			return err
+		}
+	}
+
+	var unknownKeys []string
+	if l.strict {
+		unknownKeys = l.getUnknownKeys(metadata.Unused)
+	}
+	if err := l.validate(config, unknownKeys); err != nil {
+		return err
+	}
+
+	if l.forbidDefaultSecrets {
+		if err := l.checkDefaultSecrets(config); err != nil {
+			return err
+		}
+	}
+
+	if l.summaryLogger != nil {
+		l.logSummary(config)
+	}
+	return nil
+}
+
+// transformConfig applies the migrations to the content of the config
+// file, merges the overlay and merge files into it and then applies the
+// env interpolation, the config read by viper is replaced only if the
+// content changed
+func (l *Loader) transformConfig() error {
+	raw, err := l.readConfigMap()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	if len(l.migrations) > 0 {
+		if raw, changed, err = l.migrate(raw); err != nil {
+			return err
+		}
+	}
+	// merged after migrations as only the config file is versioned
+	merged, err := l.mergeConfigFiles(raw)
+	if err != nil {
+		return err
+	}
+	changed = changed || merged
+	if l.envInterpolation {
+		raw = interpolateEnv(raw).(map[string]interface{})
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return l.replaceConfig(raw)
+}
+
+// readInConfig reads the config file or the config bytes into viper, a
+// config file which is not found is ignored unless it was set explicitly
+func (l *Loader) readInConfig() error {
+	l.rawConfig = nil
+	if l.configBytes == nil {
+		// checked within the read timeout as stat may block as well
+		notExist := fmt.Errorf("config file %s does not exist", l.configFile)
+		err := l.withReadTimeout(func() error {
+			if l.configFile != "" {
+				if _, err := os.Stat(l.configFile); os.IsNotExist(err) {
+					return notExist
+				}
+			}
+			return l.v.ReadInConfig()
+		})
+		if err == notExist {
+			return err
+		}
+		if _, ok := err.(readTimeoutError); ok {
+			return err
+		}
+		if err != nil {
+			if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+				return nil
+			}
+			// encrypted files may not be parsable, viper is only used to
+			// find the file which is read again after decrypting
+			if l.decryptor == nil {
+				return fmt.Errorf("unable to read configs using viper: %v", err)
+			}
+		}
+		// the file is read again to parse exactly the retained content
+		if l.decryptor == nil && !l.retainRawConfig {
+			return nil
+		}
+	}
+
+	content, err := l.readConfigSource()
+	if err != nil || content == nil {
+		return err
+	}
+	if l.retainRawConfig {
+		l.rawConfig = content
+	}
+	if content, err = l.decrypt(content); err != nil {
+		return err
+	}
+	if err := l.v.ReadConfig(bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("unable to read configs using viper: %v", err)
+	}
+	return nil
+}
 
-	if err := l.v.Unmarshal(config); err != nil {
+// mergeConfigFiles merges the content of the overlay and the merge files
+// in order into raw, each file is parsed as per its own extension. It
+// returns true if any file was merged.
+func (l *Loader) mergeConfigFiles(raw map[string]interface{}) (bool, error) {
+	l.mergedKeys = map[string]string{}
+	merged := false
+	files := l.mergeFiles
+	if overlay := l.getOverlayFile(); overlay != "" {
+		files = append([]string{overlay}, files...)
+	}
+
+	for _, file := range files {
+		var content []byte
+		err := l.withReadTimeout(func() (err error) {
+			content, err = ioutil.ReadFile(file)
+			return err
+		})
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return false, fmt.Errorf("unable to read config file %s: %v", file, err)
+		}
+		if l.decryptor != nil {
+			if content, err = l.decryptor.Decrypt(content); err != nil {
+				return false, fmt.Errorf("unable to decrypt config file %s: %v", file, err)
+			}
+		}
+
+		fv := viper.New()
+		fv.SetConfigType(getConfigType(file))
+		if err := fv.ReadConfig(bytes.NewReader(content)); err != nil {
+			return false, fmt.Errorf("unable to parse config file %s: %v", file, err)
+		}
+
+		mergeMaps(raw, normalizeMap(fv.AllSettings()))
+		for _, key := range fv.AllKeys() {
+			l.mergedKeys[key] = file
+		}
+		merged = true
+	}
+	return merged, nil
+}
+
+// mergeMaps merges src into dst, values in src replace the ones in dst
+// regardless of their type unless both are maps which are merged
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			if dstMap, ok := dst[key].(map[string]interface{}); ok {
+				mergeMaps(dstMap, srcMap)
+				continue
+			}
+		}
+		dst[key] = value
+	}
+}
+
+// getOverlayFile returns the path of the overlay for the environment set
+// in the overlay env variable, empty if there is none
+func (l *Loader) getOverlayFile() string {
+	file := l.v.ConfigFileUsed()
+	if l.overlayEnv == "" || file == "" {
+		return ""
+	}
+
+	env := os.Getenv(l.overlayEnv)
+	if env == "" {
+		return ""
+	}
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + env + ext
+}
+
+// getConfigKeys returns the keys of all leaf config fields in the struct,
+// including time and url fields which are loaded from strings
+func (l *Loader) getConfigKeys(config interface{}) []string {
+	if keys, ok := getScalarStructKeys(config, l.kebabKeys); ok {
+		return keys
+	}
+
+	fields := getStructFields(config, l.kebabKeys)
+	keys := make([]string, 0, len(fields))
+	for _, f := range fields {
+		keys = append(keys, f.key)
+	}
+	return keys
+}
+
+// getScalarStructKeys is a fast path of getConfigKeys for flat structs
+// having only scalar fields, it returns false if the struct has to be
+// walked by getStructFields instead e.g. for nested structs, urls or tag
+// options like squash
+func getScalarStructKeys(config interface{}, kebab bool) ([]string, bool) {
+	t := reflect.Indirect(reflect.ValueOf(config)).Type()
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := sf.Tag.Get("mapstructure")
+		if strings.ContainsAny(name, ",-") || !isScalarKind(sf.Type.Kind()) {
+			return nil, false
+		}
+		if name == "" {
+			name = sf.Name
+			if kebab {
+				name = toKebabCase(name)
+			}
+		}
+		keys = append(keys, name)
+	}
+	return keys, true
+}
+
+func isScalarKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// decodeHook returns the hooks used to decode config values into the struct
+func (l *Loader) decodeHook() mapstructure.DecodeHookFunc {
+	hooks := []mapstructure.DecodeHookFunc{
+		mapstructure.StringToTimeDurationHookFunc(),
+		stringToTimeHookFunc(),
+		stringToURLHookFunc(),
+		stringToByteSizeHookFunc(),
+	}
+	if len(l.flexibleStructs) > 0 {
+		hooks = append(hooks, flexibleStructHookFunc(l.flexibleStructs, l.kebabKeys))
+	}
+	if l.kebabKeys {
+		hooks = append(hooks, kebabKeysHookFunc())
+	}
+	hooks = append(hooks, l.decodeHooks...)
+	// after the hooks of WithDecodeHook so that strings are not split for
+	// slice types they parse e.g. net.IP
+	hooks = append(hooks, mapstructure.StringToSliceHookFunc(","))
+	return mapstructure.ComposeDecodeHookFunc(hooks...)
+}
+
+// LoadRaw loads only the values set in the config file into the given
+// struct, without applying defaults, env variables, migrations or secret
+// resolution. It is meant for tools inspecting what a config file sets
+// e.g. linters, use Load to get the effective config at runtime.
+func (l *Loader) LoadRaw(config interface{}) error {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return err
+	}
+
+	if err := l.readInConfig(); err != nil {
+		return err
+	}
+
+	raw, err := l.readConfigMap()
+	if err != nil {
+		return err
+	}
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       l.decodeHook(),
+		WeaklyTypedInput: true,
+		Result:           config,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create config decoder: %v", err)
+	}
+	if err := decoder.Decode(raw); err != nil {
 		return fmt.Errorf("unable to load config to struct: %v", err)
 	}
 	return nil
 }
 
+// RawConfig returns the raw content of the config file read during the
+// last Load, requires WithRawConfig. It is the exact content which was
+// parsed, as read before decrypting if a decryptor is set, so encrypted
+// files are never retained in plain text. The content is kept in memory
+// as long as the loader is, including any secrets of unencrypted files
+// in plain text, mask them before logging.
+func (l *Loader) RawConfig() []byte {
+	return l.rawConfig
+}
+
+// ConfigFileUsed returns the config file set with WithFile or found with
+// WithName and WithPath during the last Load, empty if there is none
+func (l *Loader) ConfigFileUsed() string {
+	return l.v.ConfigFileUsed()
+}
+
 func verifyParamIsPtrToStructElsePanic(param interface{}) error {
 	value := reflect.ValueOf(param)
 	if value.Kind() != reflect.Ptr {
@@ -137,29 +630,171 @@ func verifyParamIsPtrToStructElsePanic(param interface{}) error {
 	return nil
 }
 
+var defaultEnvKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
 func getViperWithDefaults() *viper.Viper {
 	v := viper.New()
 	v.SetConfigName("config")
 	v.SetConfigType("yaml")
-	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.SetEnvKeyReplacer(defaultEnvKeyReplacer)
 	return v
 }
 
-func getFlattenedStructKeys(config interface{}) ([]string, error) {
-	var structMap map[string]interface{}
-	if err := mapstructure.Decode(config, &structMap); err != nil {
+// readConfigMap returns the content of the config file read by viper
+// without any env or default values applied
+func (l *Loader) readConfigMap() (map[string]interface{}, error) {
+	content, err := l.readConfigFile()
+	if err != nil || content == nil {
+		return map[string]interface{}{}, err
+	}
+
+	fv := viper.New()
+	fv.SetConfigType(l.getConfigType())
+	if err := fv.ReadConfig(bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("unable to parse config file: %v", err)
+	}
+	return normalizeMap(fv.AllSettings()), nil
+}
+
+// readConfigFile returns the content of the config file read by viper,
+// or the config bytes, decrypted if a decryptor is set. Returns nil if
+// no config file was found.
+func (l *Loader) readConfigFile() ([]byte, error) {
+	content, err := l.readConfigSource()
+	if err != nil || content == nil {
 		return nil, err
 	}
+	return l.decrypt(content)
+}
 
-	flat, err := flatten.Flatten(structMap, "", flatten.DotStyle)
-	if err != nil {
+// readConfigSource returns the content of the config file read by viper
+// as is, or the config bytes. Returns nil if no config file was found.
+func (l *Loader) readConfigSource() ([]byte, error) {
+	if l.configBytes != nil {
+		return l.configBytes, nil
+	}
+
+	file := l.v.ConfigFileUsed()
+	if file == "" {
+		return nil, nil
+	}
+
+	var content []byte
+	err := l.withReadTimeout(func() (err error) {
+		content, err = ioutil.ReadFile(file)
+		return err
+	})
+	if _, ok := err.(readTimeoutError); ok {
 		return nil, err
 	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config file: %v", err)
+	}
+	return content, nil
+}
+
+// decrypt returns the content decrypted if a decryptor is set, as is
+// otherwise
+func (l *Loader) decrypt(content []byte) ([]byte, error) {
+	if l.decryptor == nil {
+		return content, nil
+	}
+
+	decrypted, err := l.decryptor.Decrypt(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt config: %v", err)
+	}
+	return decrypted, nil
+}
+
+// readTimeoutError is returned by withReadTimeout if the read does not
+// complete in time
+type readTimeoutError struct {
+	timeout time.Duration
+}
+
+func (e readTimeoutError) Error() string {
+	return fmt.Sprintf("timed out reading config after %s", e.timeout)
+}
+
+// withReadTimeout runs read and returns a readTimeoutError if it does not
+// complete within the read timeout set with WithReadTimeout
+func (l *Loader) withReadTimeout(read func() error) error {
+	if l.readTimeout <= 0 {
+		return read()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- read()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(l.readTimeout):
+		return readTimeoutError{timeout: l.readTimeout}
+	}
+}
 
-	keys := make([]string, 0, len(flat))
-	for k := range flat {
-		keys = append(keys, k)
+// replaceConfig replaces the config read by viper with the given map,
+// the map is encoded as json so the config type must be yaml or json
+func (l *Loader) replaceConfig(cfg map[string]interface{}) error {
+	content, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("unable to encode config: %v", err)
 	}
+	if err := l.v.ReadConfig(bytes.NewReader(content)); err != nil {
+		return fmt.Errorf("unable to replace config, only yaml and json configs are supported: %v", err)
+	}
+	return nil
+}
 
-	return keys, nil
+// getConfigType returns the type of the config read by viper, the one
+// set with WithType for config bytes else the one of the file
+func (l *Loader) getConfigType() string {
+	if l.configBytes != nil && l.configType != "" {
+		return l.configType
+	}
+	return getConfigType(l.v.ConfigFileUsed())
+}
+
+// getConfigType returns the config type from the file extension,
+// defaults to yaml
+func getConfigType(file string) string {
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	for _, supported := range viper.SupportedExts {
+		if ext == supported {
+			return ext
+		}
+	}
+	return "yaml"
+}
+
+// normalizeMap converts nested map[interface{}]interface{} values
+// to map[string]interface{}
+func normalizeMap(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		m[k] = normalizeValue(v)
+	}
+	return m
+}
+
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return normalizeMap(v)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = normalizeValue(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalizeValue(val)
+		}
+		return v
+	}
+	return value
 }
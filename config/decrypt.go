@@ -0,0 +1,137 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Decryptor decrypts the content of an encrypted config file e.g. one
+// encrypted with sops or age
+type Decryptor interface {
+	Decrypt(content []byte) ([]byte, error)
+}
+
+// DecryptorFunc is a function used as a Decryptor
+type DecryptorFunc func(content []byte) ([]byte, error)
+
+// Decrypt calls f(content)
+func (f DecryptorFunc) Decrypt(content []byte) ([]byte, error) {
+	return f(content)
+}
+
+// WithDecryptor decrypts the config file, or the config bytes, and the
+// files merged over it with the given decryptor before parsing them, see
+// SOPSDecryptor and AgeDecryptor. The decrypted content is kept only in
+// memory. Decryptors used with files which are not encrypted e.g. a local
+// override must return their content as is.
+func WithDecryptor(decryptor Decryptor) LoaderOption {
+	return func(l *Loader) {
+		l.decryptor = decryptor
+	}
+}
+
+// SOPSDecryptor decrypts yaml and json files encrypted with sops using
+// the sops binary, which looks up the keys as usual e.g. age keys from
+// SOPS_AGE_KEY_FILE. Content without sops metadata is returned as is.
+// Only the encrypted content is written to a temporary file for sops to
+// read, the decrypted content is read from its output.
+type SOPSDecryptor struct {
+	// Path of the sops binary, looked up in PATH if empty
+	Path string
+
+	// AgeKeyFile is the file with the age keys, sets SOPS_AGE_KEY_FILE
+	AgeKeyFile string
+
+	// Env is added to the environment of sops e.g. AWS_PROFILE=prod
+	Env []string
+}
+
+// Decrypt decrypts the content with sops
+func (d SOPSDecryptor) Decrypt(content []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(content, &doc); err == nil {
+		if _, ok := doc["sops"]; !ok {
+			return content, nil
+		}
+	}
+
+	// sops infers the format from the extension, json is valid yaml so
+	// content is parsed as json only if it looks like a json object
+	ext := ".yaml"
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+		ext = ".json"
+	}
+	f, err := ioutil.TempFile("", "config-*"+ext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create file for sops: %v", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to write file for sops: %v", err)
+	}
+
+	env := d.Env
+	if d.AgeKeyFile != "" {
+		env = append([]string{"SOPS_AGE_KEY_FILE=" + d.AgeKeyFile}, env...)
+	}
+	return runDecryptCommand("sops", d.Path, []string{"--decrypt", f.Name()}, env, nil)
+}
+
+// ageHeaders are the first lines of binary and armored age files
+var ageHeaders = [][]byte{
+	[]byte("age-encryption.org/"),
+	[]byte("-----BEGIN AGE ENCRYPTED FILE-----"),
+}
+
+// AgeDecryptor decrypts files encrypted with age using the age binary,
+// content which is not encrypted with age is returned as is
+type AgeDecryptor struct {
+	// Path of the age binary, looked up in PATH if empty
+	Path string
+
+	// IdentityFile is the file with the age identities to decrypt with
+	IdentityFile string
+}
+
+// Decrypt decrypts the content with age
+func (d AgeDecryptor) Decrypt(content []byte) ([]byte, error) {
+	encrypted := false
+	for _, header := range ageHeaders {
+		if bytes.HasPrefix(bytes.TrimSpace(content), header) {
+			encrypted = true
+		}
+	}
+	if !encrypted {
+		return content, nil
+	}
+	return runDecryptCommand("age", d.Path, []string{"--decrypt", "--identity", d.IdentityFile}, nil, content)
+}
+
+// runDecryptCommand runs the decryption tool with the input and returns
+// its output, the error includes what the tool wrote to stderr
+func runDecryptCommand(name, path string, args, env []string, input []byte) ([]byte, error) {
+	if path == "" {
+		path = name
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
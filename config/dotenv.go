@@ -0,0 +1,104 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WithDotEnv loads env variables from the given .env files on Load, for
+// local development. Env variables which are already set are not changed,
+// so the actual env takes precedence over the files and earlier files
+// take precedence over later ones. Files which do not exist are skipped.
+// The variables are set in the env of the process.
+func WithDotEnv(paths ...string) LoaderOption {
+	return func(l *Loader) {
+		l.dotEnvFiles = append(l.dotEnvFiles, paths...)
+	}
+}
+
+func (l *Loader) loadDotEnv() error {
+	for _, path := range l.dotEnvFiles {
+		content, err := ioutil.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read env file %s: %v", path, err)
+		}
+
+		vars, err := parseDotEnv(content)
+		if err != nil {
+			return fmt.Errorf("unable to parse env file %s: %v", path, err)
+		}
+		for _, kv := range vars {
+			if _, ok := os.LookupEnv(kv[0]); ok {
+				continue
+			}
+			if err := os.Setenv(kv[0], kv[1]); err != nil {
+				return fmt.Errorf("unable to set env %s from %s: %v", kv[0], path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseDotEnv parses `KEY=value` lines in order, lines can be prefixed
+// with `export` and values can be single or double quoted, with escapes
+// like `\n` expanded in double quoted values. Lines starting with `#` and
+// text after ` #` in unquoted values are comments.
+func parseDotEnv(content []byte) ([][2]string, error) {
+	var vars [][2]string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid line %d", n)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s at line %d: %v", key, n, err)
+		}
+		vars = append(vars, [2]string{key, value})
+	}
+	return vars, scanner.Err()
+}
+
+func parseDotEnvValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch quote := value[0]; quote {
+	case '"':
+		end := strings.LastIndex(value, `"`)
+		if end == 0 {
+			return "", fmt.Errorf("missing closing quote")
+		}
+		return strconv.Unquote(value[:end+1])
+
+	case '\'':
+		end := strings.LastIndex(value, "'")
+		if end == 0 {
+			return "", fmt.Errorf("missing closing quote")
+		}
+		return value[1:end], nil
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value, nil
+}
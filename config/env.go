@@ -0,0 +1,384 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeremywohl/flatten"
+	"github.com/mitchellh/mapstructure"
+)
+
+// WithFlattenStyle sets the style used to join nested keys for env
+// variables, e.g. with flatten.PathStyle the key `db.host` is bound
+// to the env variable DB/HOST instead of DB_HOST.
+// The env prefix and key replacer are applied after joining the keys.
+func WithFlattenStyle(style flatten.SeparatorStyle) LoaderOption {
+	return func(l *Loader) {
+		l.flattenStyle = style
+	}
+}
+
+// WithEnvOptIn binds only the fields tagged with `env:"+"` to env
+// variables, all other fields can be set only from the config file
+// or defaults
+func WithEnvOptIn() LoaderOption {
+	return func(l *Loader) {
+		l.envOptIn = true
+	}
+}
+
+// bindEnvKeys binds the keys to env variables as per the env tags of
+// the struct fields and returns the bound keys mapped to their env names.
+// Fields tagged with `env:"-"` are never bound, while untagged fields
+// are bound unless WithEnvOptIn is set. Fields tagged with an env name
+// e.g. `env:"DATABASE_URL"` are bound to exactly that env variable.
+func (l *Loader) bindEnvKeys(config interface{}, keys []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		tags[f.key] = f.field.Tag.Get("env")
+	}
+
+	// automatic env binds the dot style names which would take precedence
+	// over the names bound as per the flatten style
+	automaticEnv := !l.envOptIn && l.flattenStyle == flatten.DotStyle
+	bound := make(map[string]string, len(keys))
+	for _, key := range keys {
+		tag := tags[key]
+		if !l.isEnvEnabled(tag) {
+			automaticEnv = false
+			continue
+		}
+
+		if isEnvName(tag) {
+			// automatic env would also bind the prefixed env variable
+			automaticEnv = false
+			if err := l.v.BindEnv(key, tag); err != nil {
+				return nil, err
+			}
+			bound[key] = l.tagEnvKey(tag)
+			continue
+		}
+
+		if err := l.bindEnv(key); err != nil {
+			return nil, err
+		}
+		bound[key] = l.envKey(key)
+	}
+
+	// automatic env overrides any key set in the config file, even if it
+	// was not bound, so it is used only when all the keys are bound
+	if automaticEnv {
+		l.v.AutomaticEnv()
+	}
+	return bound, nil
+}
+
+// isEnvEnabled reports whether a field with the given env tag can be
+// set from env variables
+func (l *Loader) isEnvEnabled(tag string) bool {
+	if tag == "-" {
+		return false
+	}
+	return !l.envOptIn || tag != ""
+}
+
+// isEnvName reports whether the env tag sets the name of the env variable
+func isEnvName(tag string) bool {
+	return tag != "" && tag != "+" && tag != "-"
+}
+
+// fieldEnvKey returns the env variable name of the field, the name set
+// in the env tag if any, else the one derived from its key
+func (l *Loader) fieldEnvKey(f structField) string {
+	if tag := f.field.Tag.Get("env"); isEnvName(tag) {
+		return l.tagEnvKey(tag)
+	}
+	return l.envKey(f.key)
+}
+
+// tagEnvKey returns the env variable name looked up for the name set in
+// an env tag, viper applies the key replacer to bound names as well
+// e.g. `DB.HOST` is looked up as DB_HOST
+func (l *Loader) tagEnvKey(tag string) string {
+	if l.envKeyReplacer != nil {
+		return l.envKeyReplacer.Replace(tag)
+	}
+	return tag
+}
+
+func (l *Loader) bindEnv(key string) error {
+	if l.flattenStyle == flatten.DotStyle {
+		return l.v.BindEnv(key)
+	}
+	return l.v.BindEnv(key, l.envKey(key))
+}
+
+// WithEnvTypeCheck makes Load validate the values of the bound env
+// variables against the kind of their fields before loading them, so
+// that an invalid value is reported with the env variable it came from
+// e.g. "env DB_PORT=abc is not a valid int for db.port"
+func WithEnvTypeCheck() LoaderOption {
+	return func(l *Loader) {
+		l.envTypeCheck = true
+	}
+}
+
+func (l *Loader) checkEnvTypes(config interface{}, envKeys map[string]string) error {
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		env, ok := envKeys[f.key]
+		if !ok {
+			continue
+		}
+
+		val, ok := os.LookupEnv(env)
+		if !ok || val == "" {
+			continue
+		}
+		if kind, ok := checkEnvValue(f.field.Type, val); !ok {
+			return fmt.Errorf("env %s=%s is not a valid %s for %s", env, val, kind, f.key)
+		}
+	}
+	return nil
+}
+
+// checkEnvValue reports whether the env value can be parsed into the
+// given type the same way as it is decoded, along with the name of the
+// expected kind. Kinds which are not parsed from strings are not checked.
+func checkEnvValue(t reflect.Type, val string) (string, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		_, err := time.ParseDuration(val)
+		return "duration", err == nil
+	}
+	if t == byteSizeType {
+		_, err := ParseByteSize(val)
+		return "byte size", err == nil
+	}
+
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(val, 0, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(val, 0, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(val, t.Bits())
+	}
+	return t.Kind().String(), err == nil
+}
+
+// WithReportMissingEnv enables tracking of env variables which were
+// expected but not set during Load, see Loader.MissingEnv
+func WithReportMissingEnv() LoaderOption {
+	return func(l *Loader) {
+		l.reportMissingEnv = true
+	}
+}
+
+// MissingEnv returns the sorted names of env variables that were not set
+// during the last Load for keys which had no value in the config file.
+// Requires WithReportMissingEnv, only the names are reported so secrets
+// are never exposed.
+func (l *Loader) MissingEnv() []string {
+	return l.missingEnv
+}
+
+func (l *Loader) getMissingEnv(envKeys map[string]string) []string {
+	var missing []string
+	for key, env := range envKeys {
+		if l.v.InConfig(key) {
+			continue
+		}
+		if !isEnvSet(env) {
+			missing = append(missing, env)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// WithIndexedEnv enables loading slice fields from indexed env variables
+// e.g. HOSTS_0=a and HOSTS_1=b are loaded as [a b] into the slice bound
+// to HOSTS. If allowGaps is true missing indices are filled with the zero
+// value, else Load returns an error for non contiguous indices.
+// Indexed env variables take precedence over all other values.
+func WithIndexedEnv(allowGaps bool) LoaderOption {
+	return func(l *Loader) {
+		l.indexedEnv = true
+		l.indexedEnvGaps = allowGaps
+	}
+}
+
+func (l *Loader) loadIndexedEnv(config interface{}) error {
+	environ := os.Environ()
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if f.value.Kind() != reflect.Slice || !f.value.CanSet() || !l.isEnvEnabled(f.field.Tag.Get("env")) {
+			continue
+		}
+
+		prefix := l.fieldEnvKey(f) + "_"
+		indexed := map[int]string{}
+		maxIndex := -1
+		for _, kv := range environ {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 || !strings.HasPrefix(parts[0], prefix) {
+				continue
+			}
+
+			suffix := strings.TrimPrefix(parts[0], prefix)
+			index, err := strconv.Atoi(suffix)
+			if err != nil || index < 0 || strconv.Itoa(index) != suffix {
+				continue
+			}
+			indexed[index] = parts[1]
+			if index > maxIndex {
+				maxIndex = index
+			}
+		}
+		if len(indexed) == 0 {
+			continue
+		}
+		if !l.indexedEnvGaps && len(indexed) != maxIndex+1 {
+			return fmt.Errorf("non contiguous indices in env %s* for %s", prefix, f.key)
+		}
+
+		values := make([]string, maxIndex+1)
+		for i, v := range indexed {
+			values[i] = v
+		}
+
+		decoded := reflect.New(f.value.Type())
+		if err := mapstructure.WeakDecode(values, decoded.Interface()); err != nil {
+			return fmt.Errorf("unable to load indexed env %s* into %s: %v", prefix, f.key, err)
+		}
+		f.value.Set(decoded.Elem())
+	}
+	return nil
+}
+
+// WithRevealSecrets makes ExportEnv export the actual values of
+// SecretString fields instead of masking them
+func WithRevealSecrets() LoaderOption {
+	return func(l *Loader) {
+		l.revealSecrets = true
+	}
+}
+
+// ExportEnv returns the values of the given config as sorted `KEY=value`
+// env assignments, using the same env names the fields are loaded from.
+// Slices are joined with `,`, maps and fields which are not bound to env
+// variables are skipped. Timestamps are formatted as RFC3339. SecretString
+// values, fields tagged with `secret:"true"` and passwords in urls are
+// masked unless WithRevealSecrets is set.
+func (l *Loader) ExportEnv(config interface{}) ([]string, error) {
+	var env []string
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if !l.isEnvEnabled(f.field.Tag.Get("env")) {
+			continue
+		}
+
+		val, ok, err := l.formatEnvValue(f.value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to export %s: %v", f.key, err)
+		}
+		if ok && f.field.Tag.Get("secret") == "true" && !l.revealSecrets {
+			val = secretMask
+		}
+		if ok {
+			env = append(env, l.fieldEnvKey(f)+"="+val)
+		}
+	}
+	sort.Strings(env)
+	return env, nil
+}
+
+// formatEnvValue formats the value as it is parsed from env variables,
+// returns false if the value can not be set from an env variable
+func (l *Loader) formatEnvValue(value reflect.Value) (string, bool, error) {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return "", false, nil
+		}
+		return l.formatEnvValue(value.Elem())
+
+	case reflect.Map:
+		return "", false, nil
+
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			val, ok, err := l.formatEnvValue(value.Index(i))
+			if err != nil || !ok {
+				return "", ok, err
+			}
+			if strings.Contains(val, ",") {
+				return "", false, fmt.Errorf("value at index %d contains the separator ,", i)
+			}
+			values = append(values, val)
+		}
+		return strings.Join(values, ","), true, nil
+	}
+
+	if value.Type() == secretType {
+		if !l.revealSecrets {
+			return secretMask, true, nil
+		}
+		return value.String(), true, nil
+	}
+
+	switch v := value.Interface().(type) {
+	case time.Time:
+		// parsed back by the RFC3339 decode hook without losing precision
+		return v.Format(time.RFC3339Nano), true, nil
+	case url.URL:
+		if !l.revealSecrets {
+			return v.Redacted(), true, nil
+		}
+		return v.String(), true, nil
+	}
+	return fmt.Sprint(value.Interface()), true, nil
+}
+
+// envKey returns the env variable name bound to the key, same as
+// done by viper using the env prefix and key replacer
+func (l *Loader) envKey(key string) string {
+	env := key
+	if l.flattenStyle != flatten.DotStyle {
+		env = joinKey(strings.Split(key, "."), l.flattenStyle)
+	}
+	if l.envPrefix != "" {
+		env = l.envPrefix + "_" + env
+	}
+	env = strings.ToUpper(env)
+	if l.envKeyReplacer != nil {
+		env = l.envKeyReplacer.Replace(env)
+	}
+	return env
+}
+
+// isEnvSet reports if the env variable is set, empty values are
+// considered unset same as viper
+func isEnvSet(env string) bool {
+	val, ok := os.LookupEnv(env)
+	return ok && val != ""
+}
+
+// joinKey joins the key parts with the given style, same as flatten
+func joinKey(parts []string, style flatten.SeparatorStyle) string {
+	key := parts[0]
+	for _, part := range parts[1:] {
+		key += style.Before + style.Middle + part + style.After
+	}
+	return key
+}
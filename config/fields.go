@@ -0,0 +1,129 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// structField is a leaf field of a config struct along with the
+// flattened key it is loaded from and its path of Go field names
+// e.g. DB.Port
+type structField struct {
+	key   string
+	path  string
+	field reflect.StructField
+	value reflect.Value
+}
+
+// getStructFields walks the given struct (or pointer to struct) and returns
+// all leaf fields keyed by their flattened keys e.g. db.host, names of
+// fields without a mapstructure tag are converted to kebab-case if kebab
+// is true
+func getStructFields(config interface{}, kebab bool) []structField {
+	var fields []structField
+	collectStructFields(reflect.ValueOf(config), "", "", kebab, &fields)
+	return fields
+}
+
+func collectStructFields(value reflect.Value, prefix, pathPrefix string, kebab bool, fields *[]structField) {
+	value = reflect.Indirect(value)
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+
+		name, squash := fieldKey(sf, kebab)
+		if name == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if squash {
+			key = prefix
+		}
+		path := sf.Name
+		if pathPrefix != "" {
+			path = pathPrefix + "." + sf.Name
+		}
+
+		fv := value.Field(i)
+		if isNestedStruct(fv) {
+			collectStructFields(fv, key, path, kebab, fields)
+			continue
+		}
+		*fields = append(*fields, structField{key: key, path: path, field: sf, value: fv})
+	}
+}
+
+// fieldKey returns the key name of the field as per the mapstructure tag
+// and whether the field is squashed into its parent
+func fieldKey(sf reflect.StructField, kebab bool) (string, bool) {
+	tag := sf.Tag.Get("mapstructure")
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = sf.Name
+		if kebab {
+			name = toKebabCase(name)
+		}
+	}
+
+	squash := false
+	for _, opt := range parts[1:] {
+		if opt == "squash" {
+			squash = true
+		}
+	}
+	return name, squash
+}
+
+// isNestedStruct reports whether the value is a struct (or non nil pointer
+// to a struct) with exported fields that should be walked into, urls are
+// loaded from strings so they are not walked into
+func isNestedStruct(value reflect.Value) bool {
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct || value.Type() == urlType {
+		return false
+	}
+
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// toKebabCase converts a Go field name to kebab-case
+// e.g. ReadTimeout to read-timeout and HTTPServer to http-server
+func toKebabCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteRune('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/spf13/pflag"
+)
+
+// BindPFlags adds a flag named by the key of each field of the given
+// struct to the flag set, using the default and desc tags as the default
+// value and usage. Flags which are set override the config file and env
+// variables on Load, flags which are not set are ignored so that fields
+// are loaded as if there was no flag. Flags already defined in the flag
+// set are bound as is, e.g. to use a shorthand. Map fields are skipped as
+// they can not be set from a single flag value.
+func (l *Loader) BindPFlags(fs *pflag.FlagSet, config interface{}) error {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return err
+	}
+
+	if l.flags == nil {
+		l.flags = map[string]*pflag.Flag{}
+	}
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if f.value.Kind() == reflect.Map {
+			continue
+		}
+
+		if fs.Lookup(f.key) == nil {
+			def := f.field.Tag.Get("default")
+			usage := f.field.Tag.Get("desc")
+			if f.value.Kind() == reflect.Bool {
+				b, _ := strconv.ParseBool(def)
+				fs.Bool(f.key, b, usage)
+			} else {
+				fs.String(f.key, def, usage)
+			}
+		}
+		l.flags[f.key] = fs.Lookup(f.key)
+	}
+	return nil
+}
+
+// bindFlags binds the flags which were set to their keys. Flags which
+// were not set are not bound as viper would load their default values,
+// which are empty for fields without a default tag and can not be
+// decoded into e.g. durations.
+func (l *Loader) bindFlags() error {
+	for key, flag := range l.flags {
+		if !flag.Changed {
+			continue
+		}
+		if err := l.v.BindPFlag(key, flag); err != nil {
+			return fmt.Errorf("unable to bind flag %s: %v", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+)
+
+// Hash returns a deterministic hash of the given config struct which can be
+// compared to detect changes e.g. after a reload.
+// The actual values of SecretString fields are part of the hash, so the hash
+// changes when a secret is rotated.
+func Hash(config interface{}) (string, error) {
+	if kind := reflect.Indirect(reflect.ValueOf(config)).Kind(); kind != reflect.Struct {
+		return "", fmt.Errorf("require a struct for Hash, got %v", kind)
+	}
+
+	fields := getStructFields(config, false)
+	sort.SliceStable(fields, func(i, j int) bool {
+		return fields[i].key < fields[j].key
+	})
+
+	h := sha256.New()
+	for _, f := range fields {
+		// json encoding is used as it does not mask secret strings
+		// unlike the fmt package, and sorts map keys
+		value, err := json.Marshal(getHashValue(f.value))
+		if err != nil {
+			return "", fmt.Errorf("unable to encode value of %s: %v", f.key, err)
+		}
+		fmt.Fprintf(h, "%s=%s\n", f.key, value)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// getHashValue returns the value to be encoded for the hash, urls are
+// encoded as strings as their passwords are not exported
+func getHashValue(value reflect.Value) interface{} {
+	switch v := value.Interface().(type) {
+	case url.URL:
+		return v.String()
+	case *url.URL:
+		if v != nil {
+			return v.String()
+		}
+	}
+	return value.Interface()
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Holder keeps the latest loaded config which can be read concurrently
+// while it is being reloaded.
+//
+// Every successful Load stores a new snapshot, readers calling Get keep
+// using the snapshot they got and see the new one on their next Get.
+// Snapshots are shared between readers and must be treated as read only.
+type Holder struct {
+	loader    *Loader
+	newConfig func() interface{}
+
+	mu      sync.Mutex
+	current atomic.Value
+}
+
+// NewHolder returns a Holder which loads configs with the given loader
+// into the value returned by newConfig, newConfig must return a pointer
+// to a new struct of the same type on every call
+func NewHolder(loader *Loader, newConfig func() interface{}) *Holder {
+	return &Holder{
+		loader:    loader,
+		newConfig: newConfig,
+	}
+}
+
+// Load loads the config into a new snapshot and replaces the current one,
+// the current snapshot is kept if loading fails
+func (h *Holder) Load() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	config := h.newConfig()
+	if err := h.loader.Load(config); err != nil {
+		return err
+	}
+	h.current.Store(config)
+	return nil
+}
+
+// Get returns the latest loaded snapshot without locking, it returns nil
+// if no config has been loaded yet
+func (h *Holder) Get() interface{} {
+	return h.current.Load()
+}
+
+// Watch reloads the config into a new snapshot on every change of the
+// config file or the remote config read by the last Load and calls
+// onChange with the latest snapshot, which is the previous one along with
+// the error if the reload failed. It returns a function to stop watching, see Loader.Watch.
+func (h *Holder) Watch(onChange func(config interface{}, err error)) (func(), error) {
+	return h.loader.watch(func() {
+		err := h.Load()
+		onChange(h.Get(), err)
+	}, func(err error) {
+		onChange(h.Get(), err)
+	})
+}
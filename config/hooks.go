@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// WithDecodeHook adds hooks used when decoding config values into the
+// struct e.g. mapstructure.StringToIPHookFunc() to load net.IP fields.
+// Can be used multiple times, the hooks run in order after the built-in
+// ones except the one splitting comma separated strings into slices,
+// which runs last so that slice types like net.IP can be parsed.
+func WithDecodeHook(hooks ...mapstructure.DecodeHookFunc) LoaderOption {
+	return func(l *Loader) {
+		l.decodeHooks = append(l.decodeHooks, hooks...)
+	}
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	urlType  = reflect.TypeOf(url.URL{})
+)
+
+// stringToTimeHookFunc parses RFC3339 strings into time.Time values,
+// other strings are left as is for hooks added with WithDecodeHook to
+// parse them with other layouts
+func stringToTimeHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != timeType {
+			return data, nil
+		}
+
+		parsed, err := time.Parse(time.RFC3339, data.(string))
+		if err != nil {
+			return data, nil
+		}
+		return parsed, nil
+	}
+}
+
+// stringToURLHookFunc parses strings into url.URL values
+func stringToURLHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != urlType {
+			return data, nil
+		}
+
+		u, err := url.Parse(data.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid url: %v", err)
+		}
+		return *u, nil
+	}
+}
+
+// stringToByteSizeHookFunc parses human readable sizes into ByteSize
+// values, numbers are decoded as is
+func stringToByteSizeHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != byteSizeType {
+			return data, nil
+		}
+		return ParseByteSize(data.(string))
+	}
+}
+
+// hookedDefaultTypes are the types go-defaults can not set from the
+// default tag, their defaults are decoded with the decode hooks instead
+var hookedDefaultTypes = map[reflect.Type]bool{
+	byteSizeType: true,
+	timeType:     true,
+	urlType:      true,
+}
+
+// setHookedDefaults decodes the default tags of the zero fields of the
+// hookedDefaultTypes, or pointers to them, in the struct value with the
+// decode hooks e.g. `default:"512MB"` for a ByteSize field. It is run
+// after go-defaults which sets the other fields.
+func (l *Loader) setHookedDefaults(value reflect.Value) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		field := value.Field(i)
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if !hookedDefaultTypes[ft] {
+			if sf.Type.Kind() == reflect.Struct {
+				if err := l.setHookedDefaults(field); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		def, ok := sf.Tag.Lookup("default")
+		if !ok || !field.IsZero() {
+			continue
+		}
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook: l.decodeHook(),
+			Result:     field.Addr().Interface(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := decoder.Decode(def); err != nil {
+			return fmt.Errorf("invalid default of field %s: %v", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+// kebabKeysHookFunc renames kebab-case keys of maps decoded into structs,
+// so that they match names of fields without a mapstructure tag
+// e.g. `read-timeout` is renamed to `readtimeout` which mapstructure
+// matches case insensitively to the ReadTimeout field
+func kebabKeysHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		m, ok := data.(map[string]interface{})
+		if !ok || t.Kind() != reflect.Struct {
+			return data, nil
+		}
+
+		tagged := map[string]bool{}
+		for i := 0; i < t.NumField(); i++ {
+			tag := strings.Split(t.Field(i).Tag.Get("mapstructure"), ",")[0]
+			if tag != "" {
+				tagged[strings.ToLower(tag)] = true
+			}
+		}
+
+		renamed := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			if !strings.Contains(k, "-") || tagged[strings.ToLower(k)] {
+				renamed[k] = v
+			}
+		}
+		for k, v := range m {
+			if strings.Contains(k, "-") && !tagged[strings.ToLower(k)] {
+				name := strings.ReplaceAll(k, "-", "")
+				if _, ok := renamed[name]; !ok {
+					renamed[name] = v
+				}
+			}
+		}
+		return renamed, nil
+	}
+}
+
+// WithFlexibleStruct allows values of the given struct type to be set
+// either as an object or as a scalar, which is decoded into the field
+// named scalarField. e.g. when set for TLSConfig with the Enabled field,
+// both `tls: true` and `tls: {enabled: true, cert: ...}` can be loaded
+// into a TLSConfig field
+func WithFlexibleStruct(t reflect.Type, scalarField string) LoaderOption {
+	return func(l *Loader) {
+		if l.flexibleStructs == nil {
+			l.flexibleStructs = map[reflect.Type]string{}
+		}
+		l.flexibleStructs[t] = scalarField
+	}
+}
+
+// flexibleStructHookFunc wraps scalar values decoded into the flexible
+// struct types in a map with the key of their scalar field
+func flexibleStructHookFunc(types map[reflect.Type]string, kebab bool) mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		field, ok := types[t]
+		if !ok || f.Kind() == reflect.Map {
+			return data, nil
+		}
+
+		sf, ok := t.FieldByName(field)
+		if !ok {
+			return nil, fmt.Errorf("field %s not found in flexible struct %s", field, t)
+		}
+		key, _ := fieldKey(sf, kebab)
+		return map[string]interface{}{key: data}, nil
+	}
+}
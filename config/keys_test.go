@@ -0,0 +1,123 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flatConfig struct {
+	Port     int     `mapstructure:"port" default:"8080"`
+	Host     string  `mapstructure:"host" default:"localhost"`
+	Debug    bool    `mapstructure:"debug"`
+	Ratio    float64 `mapstructure:"ratio"`
+	LogLevel string
+}
+
+type nestedConfig struct {
+	Port int `mapstructure:"port"`
+	DB   struct {
+		Host    string        `mapstructure:"host"`
+		Port    int           `mapstructure:"port"`
+		Timeout time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"db"`
+	Since    time.Time         `mapstructure:"since"`
+	Endpoint *url.URL          `mapstructure:"endpoint"`
+	Labels   map[string]string `mapstructure:"labels"`
+}
+
+func TestGetConfigKeys(t *testing.T) {
+	t.Run("should return keys of flat structs", func(t *testing.T) {
+		keys := NewLoader().getConfigKeys(&flatConfig{})
+		assert.ElementsMatch(t, []string{"port", "host", "debug", "ratio", "LogLevel"}, keys)
+	})
+
+	t.Run("should return keys of nested structs with time, url and map fields as leaves", func(t *testing.T) {
+		keys := NewLoader().getConfigKeys(&nestedConfig{})
+		assert.ElementsMatch(t, []string{"port", "db.host", "db.port", "db.timeout", "since", "endpoint", "labels"}, keys)
+	})
+}
+
+func TestGetScalarStructKeys(t *testing.T) {
+	t.Run("should return same keys as the struct walk for flat structs", func(t *testing.T) {
+		for _, kebab := range []bool{false, true} {
+			keys, ok := getScalarStructKeys(&flatConfig{}, kebab)
+			assert.True(t, ok)
+
+			var walked []string
+			for _, f := range getStructFields(&flatConfig{}, kebab) {
+				walked = append(walked, f.key)
+			}
+			assert.Equal(t, walked, keys)
+		}
+	})
+
+	t.Run("should fall back for nested structs", func(t *testing.T) {
+		_, ok := getScalarStructKeys(&nestedConfig{}, false)
+		assert.False(t, ok)
+	})
+
+	t.Run("should fall back for tag options and skipped fields", func(t *testing.T) {
+		_, ok := getScalarStructKeys(&struct {
+			Port int `mapstructure:"port,omitempty"`
+		}{}, false)
+		assert.False(t, ok)
+
+		_, ok = getScalarStructKeys(&struct {
+			Port int `mapstructure:"-"`
+		}{}, false)
+		assert.False(t, ok)
+	})
+}
+
+// BenchmarkGetConfigKeys compares the fast path for flat structs with the
+// struct walk used for nested structs
+func BenchmarkGetConfigKeys(b *testing.B) {
+	b.Run("flat struct fast path", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			getScalarStructKeys(&flatConfig{}, false)
+		}
+	})
+
+	b.Run("flat struct walk", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			getStructFields(&flatConfig{}, false)
+		}
+	})
+
+	b.Run("nested struct walk", func(b *testing.B) {
+		l := NewLoader()
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.getConfigKeys(&nestedConfig{})
+		}
+	})
+}
+
+func BenchmarkLoad(b *testing.B) {
+	b.Run("flat struct", func(b *testing.B) {
+		l := NewLoader(WithConfigBytes([]byte("port: 9090\nhost: example.com\ndebug: true\n")))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var c flatConfig
+			if err := l.Load(&c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("nested struct", func(b *testing.B) {
+		l := NewLoader(WithConfigBytes([]byte("port: 9090\ndb:\n  host: db\n  timeout: 5s\nsince: 2021-06-01T10:00:00Z\n")))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var c nestedConfig
+			if err := l.Load(&c); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// versionKey is the config key holding the version of the config file
+const versionKey = "version"
+
+// Migration transforms the raw config map of a config file from
+// one version to another, Migrate must return a non nil map
+type Migration struct {
+	From    int
+	To      int
+	Migrate func(raw map[string]interface{}) (map[string]interface{}, error)
+}
+
+// WithMigrations sets the migrations used to upgrade older config files.
+// The `version` key of the config file is read and migrations are applied
+// in sequence till the latest version i.e. the highest `To` of the given
+// migrations, before loading the config into the struct. Config files
+// without a version are loaded as is.
+// Migrations are supported only for yaml and json config files.
+func WithMigrations(migrations []Migration) LoaderOption {
+	return func(l *Loader) {
+		l.migrations = migrations
+	}
+}
+
+// migrate applies the migrations to the raw config and reports whether
+// it was migrated
+func (l *Loader) migrate(raw map[string]interface{}) (map[string]interface{}, bool, error) {
+	version, ok, err := getConfigVersion(raw)
+	if err != nil || !ok {
+		return raw, false, err
+	}
+
+	latest := version
+	migrations := make(map[int]Migration, len(l.migrations))
+	for _, m := range l.migrations {
+		if m.To <= m.From {
+			return nil, false, fmt.Errorf("invalid config migration from version %d to %d", m.From, m.To)
+		}
+		migrations[m.From] = m
+		if m.To > latest {
+			latest = m.To
+		}
+	}
+	if version == latest {
+		return raw, false, nil
+	}
+
+	for version < latest {
+		m, ok := migrations[version]
+		if !ok {
+			return nil, false, fmt.Errorf("no config migration found from version %d", version)
+		}
+
+		raw, err = m.Migrate(raw)
+		if err != nil {
+			return nil, false, fmt.Errorf("unable to migrate config from version %d to %d: %v", m.From, m.To, err)
+		}
+		if raw == nil {
+			return nil, false, fmt.Errorf("unable to migrate config from version %d to %d: migration returned a nil config", m.From, m.To)
+		}
+		version = m.To
+	}
+	raw[versionKey] = version
+	return raw, true, nil
+}
+
+func getConfigVersion(raw map[string]interface{}) (int, bool, error) {
+	value, ok := raw[versionKey]
+	if !ok {
+		return 0, false, nil
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, true, nil
+	case int64:
+		return int(v), true, nil
+	case float64:
+		return int(v), true, nil
+	case string:
+		version, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid config version %q: %v", v, err)
+		}
+		return version, true, nil
+	}
+	return 0, false, fmt.Errorf("invalid config version %v", value)
+}
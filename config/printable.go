@@ -0,0 +1,149 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type printOptions struct {
+	format string
+}
+
+// PrintOption configures the output of GetPrintable
+type PrintOption func(*printOptions)
+
+// WithPrintFormat sets the output format of GetPrintable, either
+// "json" (default) or "yaml"
+func WithPrintFormat(format string) PrintOption {
+	return func(o *printOptions) {
+		o.format = format
+	}
+}
+
+// GetPrintable returns the config as indented json with all SecretString
+// values and fields tagged with `secret:"true"` masked, including the
+// ones nested in slices, maps and structs.
+// Useful to log the loaded config at startup.
+func GetPrintable(config interface{}, options ...PrintOption) (string, error) {
+	opts := printOptions{format: "json"}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	// values are masked while converting the struct, so the encoders
+	// never see the actual secrets
+	value := getPrintableValue(reflect.ValueOf(config))
+
+	var out []byte
+	var err error
+	switch opts.format {
+	case "json":
+		out, err = json.MarshalIndent(value, "", "  ")
+	case "yaml":
+		out, err = yaml.Marshal(value)
+	default:
+		return "", fmt.Errorf("unsupported print format %s", opts.format)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to encode config: %v", err)
+	}
+	return string(out), nil
+}
+
+// getPrintableValue converts the value into maps, slices and scalars keyed
+// as per the mapstructure tags with secrets masked
+func getPrintableValue(value reflect.Value) interface{} {
+	return maskSecrets(value, secretMask, false)
+}
+
+// maskSecrets is getPrintableValue with secrets replaced by the given mask,
+// names of fields without a mapstructure tag are converted to kebab-case
+// if kebab is true
+func maskSecrets(value reflect.Value, mask string, kebab bool) interface{} {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Type() == secretType {
+		return mask
+	}
+	if d, ok := value.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if u, ok := value.Interface().(url.URL); ok {
+		return u.Redacted()
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return maskSecrets(value.Elem(), mask, kebab)
+
+	case reflect.Struct:
+		if !isNestedStruct(value) {
+			return value.Interface()
+		}
+
+		m := map[string]interface{}{}
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			name, squash := fieldKey(sf, kebab)
+			if name == "-" {
+				continue
+			}
+
+			if sf.Tag.Get("secret") == "true" {
+				m[name] = mask
+				continue
+			}
+
+			fv := maskSecrets(value.Field(i), mask, kebab)
+			if squashed, ok := fv.(map[string]interface{}); ok && squash {
+				for k, v := range squashed {
+					m[k] = v
+				}
+				continue
+			}
+			m[name] = fv
+		}
+		return m
+
+	case reflect.Map:
+		if value.IsNil() {
+			return nil
+		}
+
+		m := make(map[string]interface{}, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = maskSecrets(iter.Value(), mask, kebab)
+		}
+		return m
+
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+
+		s := make([]interface{}, value.Len())
+		for i := range s {
+			s[i] = maskSecrets(value.Index(i), mask, kebab)
+		}
+		return s
+	}
+	return value.Interface()
+}
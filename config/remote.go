@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// remoteProvider implements viper.RemoteProvider
+type remoteProvider struct {
+	provider   string
+	endpoint   string
+	path       string
+	configType string
+}
+
+func (p remoteProvider) Provider() string      { return p.provider }
+func (p remoteProvider) Endpoint() string      { return p.endpoint }
+func (p remoteProvider) Path() string          { return p.path }
+func (p remoteProvider) SecretKeyring() string { return "" }
+
+// WithRemoteProvider loads config from a key value store e.g. consul or
+// etcd supported by viper. The config type is taken from the extension of
+// the path e.g. config/app.yaml, it is json if the path has none. Values
+// in the config file and env variables take precedence over the remote
+// ones. Can be used multiple times to add fallback providers, the config
+// is read from the first one which succeeds and is the one watched by
+// Watch. Requires a blank import of github.com/spf13/viper/remote.
+func WithRemoteProvider(provider, endpoint, path string) LoaderOption {
+	return func(l *Loader) {
+		l.remoteProviders = append(l.remoteProviders, remoteProvider{
+			provider:   provider,
+			endpoint:   endpoint,
+			path:       path,
+			configType: getRemoteConfigType(path),
+		})
+	}
+}
+
+// getRemoteConfigType returns the config type from the extension of the
+// path, defaults to json
+func getRemoteConfigType(path string) string {
+	ext := strings.TrimPrefix(filepath.Ext(path), ".")
+	for _, supported := range viper.SupportedExts {
+		if ext == supported {
+			return ext
+		}
+	}
+	return "json"
+}
+
+func (l *Loader) readRemoteConfig() error {
+	if viper.RemoteConfig == nil {
+		return fmt.Errorf("unable to read remote config: requires a blank import of github.com/spf13/viper/remote")
+	}
+
+	var errs []string
+	for i, p := range l.remoteProviders {
+		var settings map[string]interface{}
+		err := l.withReadTimeout(func() (err error) {
+			settings, err = p.read()
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %v", p.provider, p.path, err))
+			continue
+		}
+
+		l.setRemoteConfig(settings)
+		l.remoteProvider = &l.remoteProviders[i]
+		return nil
+	}
+	return fmt.Errorf("unable to read remote config: %s", strings.Join(errs, ", "))
+}
+
+// read returns the settings of the remote config by key
+func (p remoteProvider) read() (map[string]interface{}, error) {
+	supported := false
+	for _, provider := range viper.SupportedRemoteProviders {
+		supported = supported || p.provider == provider
+	}
+	if !supported {
+		return nil, fmt.Errorf("unsupported remote config provider")
+	}
+
+	reader, err := viper.RemoteConfig.Get(p)
+	if err != nil {
+		return nil, err
+	}
+	return p.parse(reader)
+}
+
+func (p remoteProvider) parse(in io.Reader) (map[string]interface{}, error) {
+	rv := viper.New()
+	rv.SetConfigType(p.configType)
+	if err := rv.ReadConfig(in); err != nil {
+		return nil, err
+	}
+
+	settings := map[string]interface{}{}
+	for _, key := range rv.AllKeys() {
+		settings[key] = rv.Get(key)
+	}
+	return settings, nil
+}
+
+// setRemoteConfig sets the remote settings as viper defaults so that
+// they take precedence only over the default tags, keys of the previous
+// remote config which are no longer set are cleared
+func (l *Loader) setRemoteConfig(settings map[string]interface{}) {
+	for key := range l.remoteKeys {
+		if _, ok := settings[key]; !ok {
+			l.v.SetDefault(key, nil)
+		}
+	}
+
+	l.remoteKeys = map[string]bool{}
+	for key, value := range settings {
+		l.v.SetDefault(key, value)
+		l.remoteKeys[key] = true
+	}
+}
+
+// watchRemoteConfig calls reload on every change of the remote config
+// read by the last Load, onError is called for watch errors
+func (l *Loader) watchRemoteConfig(reload func(), onError func(error)) (func(), error) {
+	p := l.remoteProvider
+	if p == nil {
+		return func() {}, nil
+	}
+
+	responses, quit := viper.RemoteConfig.WatchChannel(*p)
+	if responses == nil {
+		return nil, fmt.Errorf("unable to watch remote config %s %s", p.provider, p.path)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+
+			case resp, ok := <-responses:
+				if !ok {
+					return
+				}
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if resp.Error != nil {
+					onError(fmt.Errorf("unable to watch remote config: %v", resp.Error))
+					continue
+				}
+				reload()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if quit != nil {
+				close(quit)
+			}
+			close(done)
+		})
+	}, nil
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mcuadros/go-defaults"
+	"gopkg.in/yaml.v2"
+)
+
+// GenerateSample generates a sample config for the given struct with the
+// values of the default tags, see Loader.GenerateSample
+func GenerateSample(config interface{}, format string) ([]byte, error) {
+	return NewLoader().GenerateSample(config, format)
+}
+
+// GenerateSample generates a sample config for the type of the given
+// struct with the values of the default tags. The format can be "yaml"
+// for a config file with the `desc` tags of fields as comments, "json"
+// for a config file without descriptions or "markdown" for a table of
+// all keys with their env variables, defaults and descriptions.
+// Secrets are left empty in config files and masked in the table so the
+// sample never holds a secret or a placeholder which could be loaded as
+// one.
+func (l *Loader) GenerateSample(config interface{}, format string) ([]byte, error) {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return nil, err
+	}
+
+	sample := reflect.New(reflect.TypeOf(config).Elem())
+	defaults.SetDefaults(sample.Interface())
+	if err := l.setHookedDefaults(sample.Elem()); err != nil {
+		return nil, fmt.Errorf("unable to set defaults: %v", err)
+	}
+
+	switch format {
+	case "yaml":
+		var b strings.Builder
+		if err := l.writeSampleYAML(&b, sample.Elem(), ""); err != nil {
+			return nil, err
+		}
+		return []byte(b.String()), nil
+
+	case "json":
+		out, err := json.MarshalIndent(maskSecrets(sample, "", l.kebabKeys), "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode sample config: %v", err)
+		}
+		return append(out, '\n'), nil
+
+	case "markdown":
+		return []byte(l.getSampleMarkdown(sample.Interface())), nil
+	}
+	return nil, fmt.Errorf("unsupported sample format %s", format)
+}
+
+func (l *Loader) writeSampleYAML(b *strings.Builder, value reflect.Value, indent string) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name, squash := fieldKey(sf, l.kebabKeys)
+		if name == "-" {
+			continue
+		}
+
+		if desc := sf.Tag.Get("desc"); desc != "" {
+			for _, line := range strings.Split(desc, "\n") {
+				fmt.Fprintf(b, "%s# %s\n", indent, line)
+			}
+		}
+
+		fv := value.Field(i)
+		if isNestedStruct(fv) {
+			nestedIndent := indent
+			if !squash {
+				fmt.Fprintf(b, "%s%s:\n", indent, name)
+				nestedIndent += "  "
+			}
+			if err := l.writeSampleYAML(b, reflect.Indirect(fv), nestedIndent); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if sf.Tag.Get("secret") == "true" {
+			fv = reflect.ValueOf("")
+		}
+		val, err := l.formatSampleValue(fv)
+		if err != nil {
+			return fmt.Errorf("unable to encode sample value of %s: %v", name, err)
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", indent, name, val)
+	}
+	return nil
+}
+
+// formatSampleValue formats the value as a single line yaml value, slices
+// and maps are formatted as json which is valid yaml
+func (l *Loader) formatSampleValue(value reflect.Value) (string, error) {
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.IsNil() {
+			return "[]", nil
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			return "{}", nil
+		}
+	}
+
+	val := maskSecrets(value, "", l.kebabKeys)
+	switch val.(type) {
+	case map[string]interface{}, []interface{}:
+		out, err := json.Marshal(val)
+		return string(out), err
+	}
+
+	out, err := yaml.Marshal(val)
+	return strings.TrimSuffix(string(out), "\n"), err
+}
+
+func (l *Loader) getSampleMarkdown(config interface{}) string {
+	var b strings.Builder
+	b.WriteString("| Key | Env | Default | Description |\n")
+	b.WriteString("|-----|-----|---------|-------------|\n")
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		env := "-"
+		if l.isEnvEnabled(f.field.Tag.Get("env")) {
+			env = "`" + l.fieldEnvKey(f) + "`"
+		}
+
+		def := "-"
+		if d, ok := f.field.Tag.Lookup("default"); ok {
+			if isSecretField(f.field) {
+				d = secretMask
+			}
+			def = "`" + d + "`"
+		}
+
+		desc := strings.ReplaceAll(f.field.Tag.Get("desc"), "\n", " ")
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", f.key, env, def, strings.ReplaceAll(desc, "|", "\\|"))
+	}
+	return b.String()
+}
@@ -0,0 +1,218 @@
+package config
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const secretMask = "****************"
+
+var secretType = reflect.TypeOf(SecretString(""))
+
+// isSecretField reports whether the field holds a secret, either as a
+// SecretString or tagged with `secret:"true"`
+func isSecretField(sf reflect.StructField) bool {
+	return sf.Type == secretType || sf.Tag.Get("secret") == "true"
+}
+
+// SecretString is a string config value which is masked when printed,
+// use Secret to access the actual value
+type SecretString string
+
+// Secret returns the actual value of the secret
+func (s SecretString) Secret() string {
+	return string(s)
+}
+
+// String returns the masked value so that secrets are not leaked in logs
+func (s SecretString) String() string {
+	return secretMask
+}
+
+// GoString returns the masked value for %#v formatting
+func (s SecretString) GoString() string {
+	return secretMask
+}
+
+// Equal compares the secrets in constant time so that the comparison
+// does not leak the secret through timing, only the lengths may differ
+// in timing
+func (s SecretString) Equal(other SecretString) bool {
+	return subtle.ConstantTimeCompare([]byte(s), []byte(other)) == 1
+}
+
+// SecretResolver resolves the given secret reference to its actual value
+// e.g. a reference `vault:secret/data/db#password` could be resolved by
+// reading the password field of secret/data/db from vault
+type SecretResolver func(ctx context.Context, ref string) (string, error)
+
+// secretRefPrefix marks SecretString values which are references e.g.
+// `ref+vault:secret/data/db#password`, so that ordinary secrets which
+// look like `<scheme>:<value>` are never passed to a resolver
+const secretRefPrefix = "ref+"
+
+// WithSecretResolver sets the resolver used to resolve SecretString values
+// which are marked as references with the `ref+` prefix at load time e.g.
+// `ref+vault:secret/data/db#password` is resolved by passing the reference
+// `vault:secret/data/db#password`. Other values are loaded as is.
+func WithSecretResolver(resolver SecretResolver) LoaderOption {
+	return func(l *Loader) {
+		l.secretResolver = resolver
+	}
+}
+
+// WithSchemeResolver sets the resolver used for secret references with
+// the given scheme e.g. "vault" for `vault://secret/data/db#password` or
+// "awssm" for `awssm://my-secret`, with or without the `ref+` prefix.
+// Can be used multiple times to add resolvers for different secret
+// managers, references with other schemes are passed to the resolver set
+// with WithSecretResolver only if they have the `ref+` prefix.
+func WithSchemeResolver(scheme string, resolver SecretResolver) LoaderOption {
+	return func(l *Loader) {
+		if l.schemeResolvers == nil {
+			l.schemeResolvers = map[string]SecretResolver{}
+		}
+		l.schemeResolvers[scheme] = resolver
+	}
+}
+
+// getSecretRef returns the reference in the value without the `ref+`
+// prefix and whether the value is a reference i.e. it has the prefix or
+// a scheme registered with WithSchemeResolver
+func (l *Loader) getSecretRef(value string) (string, bool) {
+	ref := strings.TrimPrefix(value, secretRefPrefix)
+	if ref != value {
+		return ref, ref != ""
+	}
+	if i := strings.Index(ref, ":"); i > 0 {
+		_, ok := l.schemeResolvers[ref[:i]]
+		return ref, ok
+	}
+	return "", false
+}
+
+// getSecretResolver returns the resolver for the scheme of the reference,
+// else the one set with WithSecretResolver which may be nil
+func (l *Loader) getSecretResolver(ref string) SecretResolver {
+	if i := strings.Index(ref, ":"); i > 0 {
+		if resolver, ok := l.schemeResolvers[ref[:i]]; ok {
+			return resolver
+		}
+	}
+	return l.secretResolver
+}
+
+func (l *Loader) resolveSecrets(ctx context.Context, config interface{}) error {
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if f.value.Type() != secretType || !f.value.CanSet() {
+			continue
+		}
+
+		// values which are not references may be actual secrets, they
+		// must never be passed to resolvers or be part of errors
+		ref, ok := l.getSecretRef(f.value.String())
+		if !ok {
+			continue
+		}
+
+		resolver := l.getSecretResolver(ref)
+		if resolver == nil {
+			return fmt.Errorf("no resolver for secret reference %s of key %s", ref, f.key)
+		}
+
+		resolved, err := resolver(ctx, ref)
+		if err != nil {
+			return fmt.Errorf("unable to resolve secret for key %s from reference %s: %v", f.key, ref, err)
+		}
+		f.value.SetString(resolved)
+	}
+	return nil
+}
+
+// WithForbidDefaultSecrets makes Load return an error if any SecretString
+// field is still set to the value of its `default` tag, to make sure
+// placeholder secrets like `changeme` are always overridden
+func WithForbidDefaultSecrets() LoaderOption {
+	return func(l *Loader) {
+		l.forbidDefaultSecrets = true
+	}
+}
+
+func (l *Loader) checkDefaultSecrets(config interface{}) error {
+	var errs []FieldError
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if f.value.Type() != secretType {
+			continue
+		}
+
+		if def, ok := f.field.Tag.Lookup("default"); ok && f.value.String() == def {
+			errs = append(errs, FieldError{
+				Key:     f.key,
+				Rule:    "default_secret",
+				Message: "secret must be set, found default value",
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// WithSecureFilePermissions makes Load return an error if any SecretString
+// field is set from a config file which can be read or written by group
+// or others. The check is skipped on windows where file modes do not
+// reflect access permissions.
+func WithSecureFilePermissions() LoaderOption {
+	return func(l *Loader) {
+		l.secureFilePermissions = true
+	}
+}
+
+func (l *Loader) checkFilePermissions(config interface{}) error {
+	// encrypted files can be readable by others
+	if l.decryptor != nil || runtime.GOOS == "windows" {
+		return nil
+	}
+
+	// keys of secrets by the file which supplied them, merge and overlay
+	// files take precedence over the config file
+	var files []string
+	keys := map[string][]string{}
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if f.value.Type() != secretType || !l.v.InConfig(f.key) {
+			continue
+		}
+
+		file, ok := l.mergedKeys[f.key]
+		if !ok {
+			file = l.v.ConfigFileUsed()
+		}
+		// secrets set with WithConfigBytes are not read from any file
+		if file == "" {
+			continue
+		}
+		if _, ok := keys[file]; !ok {
+			files = append(files, file)
+		}
+		keys[file] = append(keys[file], f.key)
+	}
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf("unable to check permissions of config file: %v", err)
+		}
+		if mode := info.Mode().Perm(); mode&0077 != 0 {
+			return fmt.Errorf("config file %s with secrets for %s must not be accessible by group or others, found mode %s",
+				file, strings.Join(keys[file], ", "), mode)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+// Source is where the value of a config key was loaded from
+type Source int
+
+const (
+	// SourceUnset is used for keys which were not set from any source
+	// and have the zero value
+	SourceUnset Source = iota
+	// SourceDefault is used for keys set from the default struct tag
+	SourceDefault
+	// SourceFile is used for keys set in the config file
+	SourceFile
+	// SourceEnv is used for keys set from env variables
+	SourceEnv
+	// SourceFlag is used for keys set from flags bound with BindPFlags
+	SourceFlag
+	// SourceRemote is used for keys set from a remote provider
+	SourceRemote
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceDefault:
+		return "default"
+	case SourceFile:
+		return "file"
+	case SourceEnv:
+		return "env"
+	case SourceFlag:
+		return "flag"
+	case SourceRemote:
+		return "remote"
+	}
+	return "unset"
+}
+
+// LoadResult describes where the values of a loaded config came from
+type LoadResult struct {
+	// Sources maps the flattened keys of all config fields to the
+	// source of their values
+	Sources map[string]Source
+}
+
+// LoadWithResult loads the config same as Load and also returns the
+// source of the value of each key, which helps debugging precedence
+// issues e.g. an env variable with a typo which never took effect
+func (l *Loader) LoadWithResult(config interface{}) (*LoadResult, error) {
+	if err := l.Load(config); err != nil {
+		return nil, err
+	}
+
+	result := &LoadResult{Sources: map[string]Source{}}
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		result.Sources[f.key], _ = l.fieldSource(f)
+	}
+	return result, nil
+}
+
+// Report describes where the value of each key of a loaded config came
+// from, it never contains the values
+type Report struct {
+	// Keys maps the flattened keys of all config fields to their reports
+	Keys map[string]KeyReport
+}
+
+// KeyReport describes where the value of a key came from
+type KeyReport struct {
+	// Source of the value
+	Source Source
+	// Origin is the name of the config file, env variable or flag the
+	// value was set from, empty for other sources
+	Origin string
+	// Zero is true if the key has the zero value of its type
+	Zero bool
+}
+
+// LoadWithReport loads the config same as Load and also returns a report
+// of the source and origin of each key e.g. to log where the values came
+// from at startup
+func (l *Loader) LoadWithReport(config interface{}) (*Report, error) {
+	if err := l.Load(config); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Keys: map[string]KeyReport{}}
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		source, origin := l.fieldSource(f)
+		report.Keys[f.key] = KeyReport{
+			Source: source,
+			Origin: origin,
+			Zero:   f.value.IsZero(),
+		}
+	}
+	return report, nil
+}
+
+// fieldSource returns the source of the field value along with its origin
+// as per the order of precedence i.e. flags, env variables, config files,
+// remote providers and then defaults
+func (l *Loader) fieldSource(f structField) (Source, string) {
+	if flag, ok := l.flags[f.key]; ok && flag.Changed {
+		return SourceFlag, flag.Name
+	}
+	if env := l.fieldEnvKey(f); l.isEnvEnabled(f.field.Tag.Get("env")) && isEnvSet(env) {
+		return SourceEnv, env
+	}
+	if l.v.InConfig(f.key) {
+		if file, ok := l.mergedKeys[f.key]; ok {
+			return SourceFile, file
+		}
+		return SourceFile, l.v.ConfigFileUsed()
+	}
+	if l.remoteKeys[f.key] {
+		return SourceRemote, ""
+	}
+	if _, ok := f.field.Tag.Lookup("default"); ok {
+		return SourceDefault, ""
+	}
+	return SourceUnset, ""
+}
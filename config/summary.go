@@ -0,0 +1,42 @@
+package config
+
+import (
+	"github.com/odpf/salt/log"
+)
+
+// WithStartupSummary logs a summary of the config sources after every
+// successful Load i.e. the config file used, the number of keys set from
+// env variables or defaults and the number of secrets. Only counts are
+// logged so no config values are exposed.
+func WithStartupSummary(logger log.Logger) LoaderOption {
+	return func(l *Loader) {
+		l.summaryLogger = logger
+	}
+}
+
+func (l *Loader) logSummary(config interface{}) {
+	var envOverrides, defaultsApplied, secrets int
+	for _, f := range getStructFields(config, l.kebabKeys) {
+		if isSecretField(f.field) {
+			secrets++
+		}
+
+		switch source, _ := l.fieldSource(f); source {
+		case SourceEnv:
+			envOverrides++
+		case SourceDefault:
+			defaultsApplied++
+		}
+	}
+
+	file := l.v.ConfigFileUsed()
+	if file == "" {
+		file = "none"
+	}
+	l.summaryLogger.Info("loaded config",
+		"file", file,
+		"env_overrides", envOverrides,
+		"defaults", defaultsApplied,
+		"secrets", secrets,
+	)
+}
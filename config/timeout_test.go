@@ -0,0 +1,71 @@
+//go:build !windows
+// +build !windows
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/salt/config"
+)
+
+// makeFIFO creates a named pipe as the config file in a new temp dir,
+// opening it for reading blocks until it is opened for writing
+func makeFIFO(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "config.yaml")
+	if err := syscall.Mkfifo(fifo, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return dir, fifo
+}
+
+func TestReadTimeout(t *testing.T) {
+	t.Run("should return error if reading the config file blocks", func(t *testing.T) {
+		dir, fifo := makeFIFO(t)
+		t.Cleanup(func() {
+			// unblocks the abandoned read, opening fails if it is done
+			if f, err := os.OpenFile(fifo, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+				f.Close()
+			}
+		})
+
+		var c envTagsConfig
+		l := config.NewLoader(config.WithPath(dir), config.WithReadTimeout(50*time.Millisecond))
+		assert.EqualError(t, l.Load(&c), "timed out reading config after 50ms")
+	})
+
+	t.Run("should load the config file if it is read in time", func(t *testing.T) {
+		dir, fifo := makeFIFO(t)
+		go func() {
+			f, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+			if err != nil {
+				return
+			}
+			defer f.Close()
+			f.WriteString("host: fifo-host\nport: 8080\n")
+		}()
+
+		var c envTagsConfig
+		l := config.NewLoader(config.WithPath(dir), config.WithReadTimeout(5*time.Second))
+		assert.NoError(t, l.Load(&c))
+		assert.Equal(t, "fifo-host", c.Host)
+		assert.Equal(t, 8080, c.Port)
+	})
+
+	t.Run("should load a regular config file", func(t *testing.T) {
+		dir := writeConfig(t, "host: file-host\n")
+
+		var c envTagsConfig
+		l := config.NewLoader(config.WithPath(dir), config.WithReadTimeout(5*time.Second))
+		assert.NoError(t, l.Load(&c))
+		assert.Equal(t, "file-host", c.Host)
+	})
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError describes a config field which failed validation,
+// it never contains the value of the field
+type FieldError struct {
+	// Key is the flattened key of the field e.g. db.port
+	Key string
+	// Rule is the name of the failed validation rule
+	Rule string
+	// Message describes the failure
+	Message string
+}
+
+// ValidationError is returned by Load when one or more config fields
+// fail validation, use errors.As to get the individual field errors
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", fe.Key, fe.Message))
+	}
+	return "invalid config: " + strings.Join(msgs, ", ")
+}
+
+// StructValidator validates a config struct after loading e.g. a
+// *validator.Validate of github.com/go-playground/validator using the
+// `validate` struct tags
+type StructValidator interface {
+	Struct(s interface{}) error
+}
+
+// validatorFieldError is implemented by the field errors returned by
+// github.com/go-playground/validator
+type validatorFieldError interface {
+	StructNamespace() string
+	Tag() string
+}
+
+// WithValidator validates the config with the given validator after
+// loading. Errors for individual fields are returned as FieldErrors
+// keyed by the flattened keys along with the ones of required fields.
+func WithValidator(validator StructValidator) LoaderOption {
+	return func(l *Loader) {
+		l.validator = validator
+	}
+}
+
+// WithStrict makes Load return an error for keys in the config file
+// which do not match any field of the struct e.g. due to typos, along
+// with the errors of required fields
+func WithStrict() LoaderOption {
+	return func(l *Loader) {
+		l.strict = true
+	}
+}
+
+// getUnknownKeys returns the sorted keys which were not decoded into
+// the struct, excluding the version key used by migrations
+func (l *Loader) getUnknownKeys(unused []string) []string {
+	var keys []string
+	for _, key := range unused {
+		if len(l.migrations) > 0 && key == versionKey {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validate checks the required fields and runs the validator if any,
+// all invalid fields are returned in a single ValidationError along
+// with the given unknown keys
+func (l *Loader) validate(config interface{}, unknownKeys []string) error {
+	var errs []FieldError
+	for _, key := range unknownKeys {
+		errs = append(errs, FieldError{
+			Key:     key,
+			Rule:    "unknown",
+			Message: "unknown key",
+		})
+	}
+
+	fields := getStructFields(config, l.kebabKeys)
+	errs = append(errs, getRequiredErrors(fields)...)
+
+	if l.validator != nil {
+		validatorErrs, err := getValidatorErrors(l.validator.Struct(config), fields)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, validatorErrs...)
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// getValidatorErrors converts the field errors returned by the validator
+// to FieldErrors, any other error is returned as is
+func getValidatorErrors(err error, fields []structField) ([]FieldError, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	value := reflect.ValueOf(err)
+	if value.Kind() != reflect.Slice || value.Len() == 0 {
+		return nil, fmt.Errorf("unable to validate config: %v", err)
+	}
+
+	keys := make(map[string]string, len(fields))
+	for _, f := range fields {
+		keys[f.path] = f.key
+	}
+
+	errs := make([]FieldError, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		fe, ok := value.Index(i).Interface().(validatorFieldError)
+		if !ok {
+			return nil, fmt.Errorf("unable to validate config: %v", err)
+		}
+
+		// the namespace is prefixed with the name of the config struct
+		// e.g. Config.DB.Port
+		path := fe.StructNamespace()
+		if dot := strings.Index(path, "."); dot >= 0 {
+			path = path[dot+1:]
+		}
+		key, ok := keys[path]
+		if !ok {
+			key = path
+		}
+
+		errs = append(errs, FieldError{
+			Key:     key,
+			Rule:    fe.Tag(),
+			Message: fmt.Sprintf("failed on the %s rule", fe.Tag()),
+		})
+	}
+	return errs, nil
+}
+
+// getRequiredErrors returns errors for all fields tagged with
+// `required:"true"` which are still empty after loading, nil pointers and
+// empty slices or maps are considered empty
+func getRequiredErrors(fields []structField) []FieldError {
+	var errs []FieldError
+	for _, f := range fields {
+		if f.field.Tag.Get("required") != "true" || !isEmptyValue(f.value) {
+			continue
+		}
+		errs = append(errs, FieldError{
+			Key:     f.key,
+			Rule:    "required",
+			Message: "required field is not set",
+		})
+	}
+	return errs
+}
+
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce is the time to wait after the last change event before
+// reloading, so that a file being written in parts is read only once
+const watchDebounce = 100 * time.Millisecond
+
+// Watch watches the config file read by the last Load and reloads the
+// config into the given struct on every change, onChange is called after
+// each reload with the error if any. The struct is updated only if the
+// reload succeeds, use a Holder instead if the config is read by other
+// goroutines while reloading.
+// The remote config read by the last Load is watched as well.
+// It returns a function to stop watching, Watch is a no-op if no config
+// file or remote config was read e.g. when the config is set only from
+// env variables.
+func (l *Loader) Watch(config interface{}, onChange func(error)) (func(), error) {
+	if err := verifyParamIsPtrToStructElsePanic(config); err != nil {
+		return nil, err
+	}
+
+	return l.watch(func() {
+		fresh := reflect.New(reflect.TypeOf(config).Elem())
+		err := l.Load(fresh.Interface())
+		if err == nil {
+			reflect.ValueOf(config).Elem().Set(fresh.Elem())
+		}
+		onChange(err)
+	}, onChange)
+}
+
+// watch calls reload after changes to the config file or the remote
+// config read by the last Load, calls to reload are serialized
+func (l *Loader) watch(reload func(), onError func(error)) (func(), error) {
+	var mu sync.Mutex
+	serialized := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		reload()
+	}
+
+	stopFile, err := l.watchConfigFile(serialized, onError)
+	if err != nil {
+		return nil, err
+	}
+	stopRemote, err := l.watchRemoteConfig(serialized, onError)
+	if err != nil {
+		stopFile()
+		return nil, err
+	}
+	return func() {
+		stopRemote()
+		stopFile()
+	}, nil
+}
+
+// watchConfigFile calls reload after changes to the config file read by
+// the last Load are debounced, onError is called for watch errors.
+// Calls to reload are serialized and never made after stopping.
+func (l *Loader) watchConfigFile(reload func(), onError func(error)) (func(), error) {
+	file := l.v.ConfigFileUsed()
+	if file == "" {
+		return func() {}, nil
+	}
+	file = filepath.Clean(file)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create config file watcher: %v", err)
+	}
+	// the dir is watched as editors and k8s config maps replace the file
+	// instead of writing to it
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("unable to watch config file: %v", err)
+	}
+
+	done := make(chan struct{})
+	var mu sync.Mutex
+	debounced := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		select {
+		case <-done:
+			return
+		default:
+		}
+		reload()
+	}
+
+	go func() {
+		var timer *time.Timer
+		realFile, _ := filepath.EvalSymlinks(file)
+		for {
+			select {
+			case <-done:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				// a changed symlink target is also a change of the file
+				currentFile, _ := filepath.EvalSymlinks(file)
+				if filepath.Clean(event.Name) != file && currentFile == realFile {
+					continue
+				}
+				realFile = currentFile
+
+				if timer == nil {
+					timer = time.AfterFunc(watchDebounce, debounced)
+				} else {
+					timer.Reset(watchDebounce)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				onError(fmt.Errorf("unable to watch config file: %v", err))
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			watcher.Close()
+		})
+	}, nil
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/odpf/salt/config"
+)
+
+// Config to connect to a database, it can be embedded in a service config
+// and loaded with config.Loader, the password is masked by GetPrintable
+type Config struct {
+	// Driver is the name of a registered database/sql driver
+	Driver string `mapstructure:"driver" default:"postgres"`
+
+	// URL of the database without the password
+	// e.g. postgres://user@localhost:5432/app?sslmode=disable
+	URL string `mapstructure:"url"`
+
+	// Password is set as the password of the user in URL if not empty
+	Password config.SecretString `mapstructure:"password"`
+
+	MaxOpenConns    int           `mapstructure:"max_open_conns" default:"10"`
+	MaxIdleConns    int           `mapstructure:"max_idle_conns" default:"2"`
+	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime" default:"30m"`
+	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time" default:"5m"`
+}
+
+// dataSourceName returns the URL with the password set, it must never be
+// logged or returned in errors
+func (c Config) dataSourceName() (string, error) {
+	if c.Password == "" {
+		return c.URL, nil
+	}
+
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("invalid database url: %v", err)
+	}
+	u.User = url.UserPassword(u.User.Username(), c.Password.Secret())
+	return u.String(), nil
+}
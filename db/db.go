@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DB is a pool of database connections, all the methods of sqlx.DB and
+// sql.DB can be used on it
+type DB struct {
+	*sqlx.DB
+}
+
+// New opens a connection pool to the database as per the config and
+// verifies the connection, the driver must be registered by importing it
+func New(c Config) (*DB, error) {
+	dsn, err := c.dataSourceName()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlxDB, err := sqlx.Open(c.Driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open database: %v", err)
+	}
+	sqlxDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlxDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlxDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlxDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	if err := sqlxDB.Ping(); err != nil {
+		sqlxDB.Close()
+		return nil, fmt.Errorf("unable to connect to database: %v", err)
+	}
+	return &DB{DB: sqlxDB}, nil
+}
+
+// WithTxn runs fn in a transaction which is committed if fn returns nil
+// and rolled back if it returns an error or panics, the error of fn is
+// returned as is
+func (db *DB) WithTxn(ctx context.Context, opts *sql.TxOptions, fn func(tx *sqlx.Tx) error) error {
+	tx, err := db.BeginTxx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %v", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit transaction: %v", err)
+	}
+	return nil
+}
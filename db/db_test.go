@@ -0,0 +1,321 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/odpf/salt/config"
+	"github.com/odpf/salt/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+	// registered to test the advisory lock taken for postgres
+	sql.Register("pgx", fakeDriver{})
+}
+
+// fakeDBs are the states of the fake databases by the path of their url
+var fakeDBs = struct {
+	sync.Mutex
+	m map[string]*fakeDB
+}{m: map[string]*fakeDB{}}
+
+// fakeDB records the data source names it is opened with and the
+// statements it runs, it keeps the versions inserted into the migrations
+// table once committed
+type fakeDB struct {
+	mu       sync.Mutex
+	dsns     []string
+	queries  []string
+	versions []string
+	failExec string
+}
+
+// newFakeDB returns a fake database along with a config to connect to it
+// with the given driver
+func newFakeDB(t *testing.T, driver string) (*fakeDB, db.Config) {
+	t.Helper()
+	fakeDBs.Lock()
+	defer fakeDBs.Unlock()
+
+	path := fmt.Sprintf("/%d", len(fakeDBs.m))
+	fake := &fakeDB{}
+	fakeDBs.m[path] = fake
+	return fake, db.Config{Driver: driver, URL: "fake://app@localhost" + path, MaxOpenConns: 2}
+}
+
+func (f *fakeDB) record(query string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, query)
+}
+
+func (f *fakeDB) getQueries() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.queries...)
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	fakeDBs.Lock()
+	fake, ok := fakeDBs.m[u.Path]
+	fakeDBs.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown database %s", u.Path)
+	}
+
+	fake.mu.Lock()
+	fake.dsns = append(fake.dsns, dsn)
+	fake.mu.Unlock()
+	return &fakeConn{db: fake}, nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+	// pending versions to record on commit
+	pending []string
+	inTx    bool
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.record("BEGIN")
+	c.inTx = true
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.record("COMMIT")
+	c.db.mu.Lock()
+	c.db.versions = append(c.db.versions, c.pending...)
+	c.db.mu.Unlock()
+	c.pending, c.inTx = nil, false
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.db.record("ROLLBACK")
+	c.pending, c.inTx = nil, false
+	return nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query)
+	if c.db.failExec != "" && strings.Contains(query, c.db.failExec) {
+		return nil, errors.New("exec failed")
+	}
+
+	if strings.HasPrefix(query, "INSERT INTO schema_migrations") {
+		version := args[0].Value.(string)
+		if !c.inTx {
+			c.db.mu.Lock()
+			c.db.versions = append(c.db.versions, version)
+			c.db.mu.Unlock()
+		}
+		c.pending = append(c.pending, version)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query)
+	if query != "SELECT version FROM schema_migrations" {
+		return nil, fmt.Errorf("unexpected query %s", query)
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	return &fakeRows{versions: append([]string(nil), c.db.versions...)}, nil
+}
+
+type fakeRows struct {
+	versions []string
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.versions) == 0 {
+		return io.EOF
+	}
+	dest[0], r.versions = r.versions[0], r.versions[1:]
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should set the password on the url", func(t *testing.T) {
+		fake, c := newFakeDB(t, "fakedb")
+		c.Password = "s3cr3t"
+
+		d, err := db.New(c)
+		assert.NoError(t, err)
+		defer d.Close()
+		assert.Equal(t, "fakedb", d.DriverName())
+		assert.Equal(t, []string{strings.Replace(c.URL, "app@", "app:s3cr3t@", 1)}, fake.dsns)
+	})
+
+	t.Run("should use the url as is without a password", func(t *testing.T) {
+		fake, c := newFakeDB(t, "fakedb")
+
+		d, err := db.New(c)
+		assert.NoError(t, err)
+		defer d.Close()
+		assert.Equal(t, []string{c.URL}, fake.dsns)
+	})
+
+	t.Run("should return error for invalid url without the password", func(t *testing.T) {
+		_, err := db.New(db.Config{Driver: "fakedb", URL: "fake://app@localhost:port/0", Password: "s3cr3t"})
+		assert.EqualError(t, err, `invalid database url: invalid port ":port" after host`)
+	})
+
+	t.Run("should return error for unknown driver", func(t *testing.T) {
+		_, err := db.New(db.Config{Driver: "unknown", URL: "fake://app@localhost/0"})
+		assert.EqualError(t, err, `unable to open database: sql: unknown driver "unknown" (forgotten import?)`)
+	})
+
+	t.Run("should mask the password in printable config", func(t *testing.T) {
+		c := db.Config{Driver: "fakedb", URL: "fake://app@localhost/0", Password: "s3cr3t"}
+
+		printable, err := config.GetPrintable(&c)
+		assert.NoError(t, err)
+		assert.Contains(t, printable, "****************")
+		assert.NotContains(t, printable, "s3cr3t")
+	})
+}
+
+func newDB(t *testing.T, driver string) (*fakeDB, *db.DB) {
+	t.Helper()
+	fake, c := newFakeDB(t, driver)
+	d, err := db.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { d.Close() })
+	return fake, d
+}
+
+func TestWithTxn(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should commit if fn returns nil", func(t *testing.T) {
+		fake, d := newDB(t, "fakedb")
+
+		err := d.WithTxn(ctx, nil, func(tx *sqlx.Tx) error {
+			_, err := tx.ExecContext(ctx, "UPDATE accounts SET balance = 10")
+			return err
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"BEGIN", "UPDATE accounts SET balance = 10", "COMMIT"}, fake.getQueries())
+	})
+
+	t.Run("should rollback and return the error of fn as is", func(t *testing.T) {
+		fake, d := newDB(t, "fakedb")
+		errFailed := errors.New("failed")
+
+		err := d.WithTxn(ctx, nil, func(tx *sqlx.Tx) error {
+			return errFailed
+		})
+		assert.Equal(t, errFailed, err)
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fake.getQueries())
+	})
+
+	t.Run("should rollback and panic again if fn panics", func(t *testing.T) {
+		fake, d := newDB(t, "fakedb")
+
+		assert.Panics(t, func() {
+			d.WithTxn(ctx, nil, func(tx *sqlx.Tx) error {
+				panic("failed")
+			})
+		})
+		assert.Equal(t, []string{"BEGIN", "ROLLBACK"}, fake.getQueries())
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	migrations := fstest.MapFS{
+		"0002_add_email.sql":    {Data: []byte("ALTER TABLE users ADD email TEXT")},
+		"0001_create_users.sql": {Data: []byte("CREATE TABLE users (id INT)")},
+		"0003_create_teams.sql": {Data: []byte("CREATE TABLE teams (id INT)")},
+		"README.md":             {Data: []byte("not a migration")},
+	}
+
+	t.Run("should apply migrations in order of their names", func(t *testing.T) {
+		fake, d := newDB(t, "fakedb")
+
+		assert.NoError(t, d.Migrate(ctx, migrations))
+		assert.Equal(t, []string{"0001_create_users", "0002_add_email", "0003_create_teams"}, fake.versions)
+
+		var applied []string
+		for _, query := range fake.getQueries() {
+			if strings.HasPrefix(query, "CREATE TABLE users") || strings.HasPrefix(query, "ALTER") || strings.HasPrefix(query, "CREATE TABLE teams") {
+				applied = append(applied, query)
+			}
+		}
+		assert.Equal(t, []string{"CREATE TABLE users (id INT)", "ALTER TABLE users ADD email TEXT", "CREATE TABLE teams (id INT)"}, applied)
+	})
+
+	t.Run("should skip applied migrations", func(t *testing.T) {
+		fake, d := newDB(t, "fakedb")
+		fake.versions = []string{"0001_create_users", "0002_add_email"}
+
+		assert.NoError(t, d.Migrate(ctx, migrations))
+		assert.Equal(t, []string{"0001_create_users", "0002_add_email", "0003_create_teams"}, fake.versions)
+		for _, query := range fake.getQueries() {
+			assert.NotContains(t, query, "users")
+		}
+	})
+
+	t.Run("should stop at a failing migration without recording it", func(t *testing.T) {
+		fake, d := newDB(t, "fakedb")
+		fake.failExec = "ALTER"
+
+		err := d.Migrate(ctx, migrations)
+		assert.EqualError(t, err, "unable to apply migration 0002_add_email.sql: exec failed")
+		assert.Equal(t, []string{"0001_create_users"}, fake.versions)
+		assert.NotContains(t, fake.getQueries(), "CREATE TABLE teams (id INT)")
+	})
+
+	t.Run("should hold the advisory lock of postgres while migrating", func(t *testing.T) {
+		fake, d := newDB(t, "pgx")
+		fake.versions = []string{"0001_create_users", "0002_add_email"}
+
+		assert.NoError(t, d.Migrate(ctx, migrations))
+		queries := fake.getQueries()
+		assert.Equal(t, []string{
+			"CREATE TABLE IF NOT EXISTS schema_migrations (version VARCHAR(255) PRIMARY KEY, applied_at TIMESTAMP NOT NULL)",
+			"SELECT pg_advisory_lock($1)",
+			"SELECT version FROM schema_migrations",
+			"BEGIN",
+			"CREATE TABLE teams (id INT)",
+			"INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)",
+			"COMMIT",
+			"SELECT pg_advisory_unlock($1)",
+		}, queries)
+	})
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const migrationsTable = "schema_migrations"
+
+// migrationLock holds the statements to take and release a lock which is
+// held by the connection it is taken on
+type migrationLock struct {
+	lock   string
+	unlock string
+	key    interface{}
+}
+
+var (
+	postgresMigrationLock = migrationLock{
+		lock:   "SELECT pg_advisory_lock($1)",
+		unlock: "SELECT pg_advisory_unlock($1)",
+		key:    int64(crc32.ChecksumIEEE([]byte(migrationsTable))),
+	}
+
+	// migrationLocks by driver name
+	migrationLocks = map[string]migrationLock{
+		"postgres": postgresMigrationLock,
+		"pgx":      postgresMigrationLock,
+		"mysql": {
+			lock:   "SELECT GET_LOCK(?, -1)",
+			unlock: "SELECT RELEASE_LOCK(?)",
+			key:    migrationsTable,
+		},
+	}
+)
+
+// Migrate applies the migrations in fsys which have not been applied yet.
+// Migrations are the .sql files at the root of fsys applied in the order
+// of their names e.g. 0001_create_users.sql, each one in a transaction
+// along with recording its name in the schema_migrations table.
+// Use fs.Sub to migrate from a directory of an embedded file system.
+// A file with multiple statements requires a driver that supports them.
+// With postgres and mysql, replicas starting at the same time take turns
+// holding a lock while migrating, with other drivers a replica fails on
+// the primary key of the migrations table if it races with another one.
+func (db *DB) Migrate(ctx context.Context, fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return fmt.Errorf("unable to list migrations: %v", err)
+	}
+
+	createTable := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version VARCHAR(255) PRIMARY KEY, applied_at TIMESTAMP NOT NULL)", migrationsTable)
+	if _, err := db.ExecContext(ctx, createTable); err != nil {
+		return fmt.Errorf("unable to create %s table: %v", migrationsTable, err)
+	}
+
+	unlock, err := db.lockMigrations(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// the applied migrations are read while holding the lock so that the
+	// ones applied by another replica are skipped
+	applied, err := db.getAppliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	insert := db.Rebind(fmt.Sprintf("INSERT INTO %s (version, applied_at) VALUES (?, ?)", migrationsTable))
+	for _, file := range files {
+		version := strings.TrimSuffix(file, ".sql")
+		if applied[version] {
+			continue
+		}
+
+		query, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("unable to read migration %s: %v", file, err)
+		}
+
+		err = db.WithTxn(ctx, nil, func(tx *sqlx.Tx) error {
+			if _, err := tx.ExecContext(ctx, string(query)); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, insert, version, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("unable to apply migration %s: %v", file, err)
+		}
+	}
+	return nil
+}
+
+// lockMigrations takes the migration lock of the driver if it has one on
+// a dedicated connection and returns the func to release it
+func (db *DB) lockMigrations(ctx context.Context) (func(), error) {
+	lock, ok := migrationLocks[db.DriverName()]
+	if !ok {
+		return func() {}, nil
+	}
+
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock migrations: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, lock.lock, lock.key); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("unable to lock migrations: %v", err)
+	}
+
+	return func() {
+		// the lock is released even if ctx is done, the connection is
+		// discarded if that fails so that the lock is not kept in the pool
+		if _, err := conn.ExecContext(context.Background(), lock.unlock, lock.key); err != nil {
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+func (db *DB) getAppliedMigrations(ctx context.Context) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", migrationsTable))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read applied migrations: %v", err)
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("unable to read applied migrations: %v", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read applied migrations: %v", err)
+	}
+	return applied, nil
+}
@@ -4,12 +4,14 @@ go 1.16
 
 require (
 	github.com/charmbracelet/glamour v0.3.0
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/gorilla/mux v1.8.0
 	github.com/grpc-ecosystem/go-grpc-middleware v1.3.0
 	github.com/grpc-ecosystem/go-grpc-prometheus v1.2.0
 	github.com/grpc-ecosystem/grpc-gateway/v2 v2.6.0
 	github.com/hashicorp/go-version v1.3.0
 	github.com/jeremywohl/flatten v1.0.1
+	github.com/jmoiron/sqlx v1.3.4
 	github.com/mcuadros/go-defaults v1.2.0
 	github.com/mitchellh/mapstructure v1.4.1
 	github.com/muesli/termenv v0.9.0
@@ -25,4 +27,5 @@ require (
 	go.buf.build/odpf/gw/odpf/proton v1.1.9
 	go.uber.org/zap v1.19.0
 	google.golang.org/grpc v1.40.0
+	gopkg.in/yaml.v2 v2.4.0
 )
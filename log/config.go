@@ -0,0 +1,99 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config configures a logger, it is meant to be embedded in the config
+// struct of a service under a `log` key and loaded with config.Loader,
+// from log.level, log.format and log.output_paths in the config file or
+// the LOG_LEVEL, LOG_FORMAT and LOG_OUTPUT_PATHS env variables
+type Config struct {
+	// Level is one of debug, info, warn or error
+	Level string `mapstructure:"level" default:"info"`
+
+	// Format is either json or console
+	Format string `mapstructure:"format" default:"json"`
+
+	// OutputPaths are the files logs are written to, stdout and stderr
+	// refer to the standard streams, logs are written to stdout if empty
+	OutputPaths []string `mapstructure:"output_paths"`
+}
+
+func (c Config) getOutputPaths() []string {
+	if len(c.OutputPaths) == 0 {
+		return []string{"stdout"}
+	}
+	return c.OutputPaths
+}
+
+// ZapWithLogConfig configures the zap logger as per the given Config,
+// it panics if the config is invalid
+func ZapWithLogConfig(c Config) Option {
+	return func(z interface{}) {
+		var level zapcore.Level
+		if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+			panic(err)
+		}
+
+		conf := zap.NewProductionConfig()
+		conf.Level = zap.NewAtomicLevelAt(level)
+		switch c.Format {
+		case "json":
+		case "console":
+			conf.Encoding = "console"
+			conf.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+		default:
+			panic(fmt.Errorf("unsupported log format %s", c.Format))
+		}
+		conf.OutputPaths = c.getOutputPaths()
+
+		logger, err := conf.Build()
+		if err != nil {
+			panic(err)
+		}
+		z.(*Zap).conf = conf
+		z.(*Zap).log = logger.Sugar()
+	}
+}
+
+// LogrusWithLogConfig configures the logrus logger as per the given
+// Config, it panics if the config is invalid or an output file can not
+// be opened
+func LogrusWithLogConfig(c Config) Option {
+	return func(logger interface{}) {
+		LogrusWithLevel(c.Level)(logger)
+
+		switch c.Format {
+		case "json":
+			LogrusWithFormatter(&logrus.JSONFormatter{})(logger)
+		case "console":
+			LogrusWithFormatter(&logrus.TextFormatter{})(logger)
+		default:
+			panic(fmt.Errorf("unsupported log format %s", c.Format))
+		}
+
+		var writers []io.Writer
+		for _, path := range c.getOutputPaths() {
+			switch path {
+			case "stdout":
+				writers = append(writers, os.Stdout)
+			case "stderr":
+				writers = append(writers, os.Stderr)
+			default:
+				f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					panic(err)
+				}
+				writers = append(writers, f)
+			}
+		}
+		LogrusWithWriter(io.MultiWriter(writers...))(logger)
+	}
+}
@@ -0,0 +1,20 @@
+package log
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx which carries the logger, use it along
+// with With to pass request scoped fields down the call chain
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, a Noop logger if there
+// is none
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return logger
+	}
+	return NewNoop()
+}
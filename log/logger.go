@@ -45,4 +45,8 @@ type Logger interface {
 
 	// Writer used to print logs
 	Writer() io.Writer
+
+	// With returns a logger which adds the alternating key/value pairs
+	// to every message, the receiver is left unchanged
+	With(args ...interface{}) Logger
 }
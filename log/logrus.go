@@ -7,11 +7,15 @@ import (
 )
 
 type Logrus struct {
-	log *logrus.Logger
+	log    *logrus.Logger
+	fields map[string]interface{}
 }
 
 func (l Logrus) getFields(args ...interface{}) map[string]interface{} {
 	fieldMap := map[string]interface{}{}
+	for k, v := range l.fields {
+		fieldMap[k] = v
+	}
 	if len(args) > 1 && len(args)%2 == 0 {
 		for i := 1; i < len(args); i += 2 {
 			fieldMap[args[i-1].(string)] = args[i]
@@ -47,6 +51,13 @@ func (l *Logrus) Writer() io.Writer {
 	return l.log.Writer()
 }
 
+func (l *Logrus) With(args ...interface{}) Logger {
+	return &Logrus{
+		log:    l.log,
+		fields: l.getFields(args...),
+	}
+}
+
 func LogrusWithLevel(level string) Option {
 	return func(logger interface{}) {
 		logLevel, err := logrus.ParseLevel(level)
@@ -3,7 +3,10 @@ package log_test
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/sirupsen/logrus"
@@ -74,4 +77,60 @@ func TestLogrus(t *testing.T) {
 		foo.Flush()
 		assert.Equal(t, "level=error msg=\"request failed\"\n", b.String())
 	})
+	t.Run("should add fields of With to every message", func(t *testing.T) {
+		var b bytes.Buffer
+		foo := bufio.NewWriter(&b)
+
+		logger := log.NewLogrus(log.LogrusWithLevel("info"), log.LogrusWithWriter(foo), log.LogrusWithFormatter(&logrus.TextFormatter{
+			DisableTimestamp: true,
+		}))
+		reqLogger := logger.With("request_id", "abc")
+		reqLogger.Info("started", "path", "/ping")
+		logger.Info("done")
+		foo.Flush()
+
+		assert.Equal(t, "level=info msg=started path=/ping request_id=abc\nlevel=info msg=done\n", b.String())
+	})
+	t.Run("should configure logger from log config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+
+		logger := log.NewLogrus(log.LogrusWithLogConfig(log.Config{
+			Level:       "warn",
+			Format:      "json",
+			OutputPaths: []string{path},
+		}))
+		logger.Info("hidden")
+		logger.Warn("disk full", "free", "1%")
+
+		assert.Equal(t, "warning", logger.Level())
+		out, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), `"msg":"disk full"`)
+		assert.Contains(t, string(out), `"free":"1%"`)
+		assert.NotContains(t, string(out), "hidden")
+	})
+	t.Run("should panic on unsupported log format", func(t *testing.T) {
+		assert.Panics(t, func() {
+			log.NewLogrus(log.LogrusWithLogConfig(log.Config{Level: "info", Format: "xml"}))
+		})
+	})
+}
+
+func TestContext(t *testing.T) {
+	t.Run("should return logger from context", func(t *testing.T) {
+		var b bytes.Buffer
+		foo := bufio.NewWriter(&b)
+
+		logger := log.NewLogrus(log.LogrusWithWriter(foo), log.LogrusWithFormatter(&logrus.TextFormatter{
+			DisableTimestamp: true,
+		}))
+		ctx := log.NewContext(context.Background(), logger.With("request_id", "abc"))
+		log.FromContext(ctx).Info("hello")
+		foo.Flush()
+
+		assert.Equal(t, "level=info msg=hello request_id=abc\n", b.String())
+	})
+	t.Run("should return noop logger if context has none", func(t *testing.T) {
+		assert.IsType(t, &log.Noop{}, log.FromContext(context.Background()))
+	})
 }
@@ -20,6 +20,10 @@ func (n *Noop) Writer() io.Writer {
 	return ioutil.Discard
 }
 
+func (n *Noop) With(args ...interface{}) Logger {
+	return n
+}
+
 // NewNoop returns a no operation logger, useful in tests
 func NewNoop(opts ...Option) *Noop {
 	return &Noop{}
@@ -39,6 +39,13 @@ func (z Zap) Writer() io.Writer {
 	panic("not supported")
 }
 
+func (z Zap) With(args ...interface{}) Logger {
+	return &Zap{
+		log:  z.log.With(args...),
+		conf: z.conf,
+	}
+}
+
 func ZapWithConfig(conf zap.Config, opt zap.Option) Option {
 	return func(z interface{}) {
 		z.(*Zap).conf = conf
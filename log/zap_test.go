@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/url"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -41,15 +43,21 @@ func (m zapClock) NewTicker(duration time.Duration) *time.Ticker {
 	return time.NewTicker(duration)
 }
 
+// zapSinks is the number of registered mock writers, sinks can not be
+// registered again so each writer is registered with its own scheme
+var zapSinks int
+
 func buildBufferedZapOption(writer io.Writer, t time.Time) log.Option {
 	config := zap.NewDevelopmentConfig()
 	config.DisableCaller = true
 	// register mock writer
-	_ = zap.RegisterSink(bufWriterKey, func(u *url.URL) (zap.Sink, error) {
+	zapSinks++
+	scheme := fmt.Sprintf("%s%d", bufWriterKey, zapSinks)
+	_ = zap.RegisterSink(scheme, func(u *url.URL) (zap.Sink, error) {
 		return zapBufWriter{writer}, nil
 	})
 	// build a valid custom path
-	customPath := fmt.Sprintf("%s:", bufWriterKey)
+	customPath := fmt.Sprintf("%s:", scheme)
 	config.OutputPaths = []string{customPath}
 
 	return log.ZapWithConfig(config, zap.WithClock(&zapClock{
@@ -70,4 +78,61 @@ func TestZap(t *testing.T) {
 
 		assert.Equal(t, mockedTime.Format("2006-01-02T15:04:05.000Z0700")+"\tINFO\thello\t{\"wor\": \"ld\"}\n", b.String())
 	})
+	t.Run("should add fields of With to every message", func(t *testing.T) {
+		var b bytes.Buffer
+		bWriter := bufio.NewWriter(&b)
+
+		zapper := log.NewZap(buildBufferedZapOption(bWriter, mockedTime))
+		reqLogger := zapper.With("request_id", "abc")
+		reqLogger.Info("started", "path", "/ping")
+		zapper.Info("done")
+		bWriter.Flush()
+
+		ts := mockedTime.Format("2006-01-02T15:04:05.000Z0700")
+		assert.Equal(t, ts+"\tINFO\tstarted\t{\"request_id\": \"abc\", \"path\": \"/ping\"}\n"+
+			ts+"\tINFO\tdone\n", b.String())
+		assert.Equal(t, zapper.Level(), reqLogger.Level())
+	})
+	t.Run("should configure logger from log config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+
+		zapper := log.NewZap(log.ZapWithLogConfig(log.Config{
+			Level:       "info",
+			Format:      "json",
+			OutputPaths: []string{path},
+		}))
+		zapper.Debug("hidden")
+		zapper.Info("disk full", "free", "1%")
+
+		assert.Equal(t, "info", zapper.Level())
+		out, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), `"msg":"disk full"`)
+		assert.Contains(t, string(out), `"free":"1%"`)
+		assert.NotContains(t, string(out), "hidden")
+	})
+	t.Run("should configure console format from log config", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+
+		zapper := log.NewZap(log.ZapWithLogConfig(log.Config{
+			Level:       "debug",
+			Format:      "console",
+			OutputPaths: []string{path},
+		}))
+		zapper.Debug("connecting", "host", "localhost")
+
+		assert.Equal(t, "debug", zapper.Level())
+		out, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(out), "\tDEBUG\t")
+		assert.Contains(t, string(out), "connecting\t{\"host\": \"localhost\"}")
+	})
+	t.Run("should panic on invalid log config", func(t *testing.T) {
+		assert.Panics(t, func() {
+			log.NewZap(log.ZapWithLogConfig(log.Config{Level: "info", Format: "xml"}))
+		})
+		assert.Panics(t, func() {
+			log.NewZap(log.ZapWithLogConfig(log.Config{Level: "loud", Format: "json"}))
+		})
+	})
 }
@@ -1,7 +1,37 @@
 package server
 
-// Config to set the host and port for different servers
+import (
+	"net/http"
+	"time"
+)
+
+// Config to set the host and port for different servers, it can be
+// embedded in a service config and loaded with config.Loader
 type Config struct {
-	Port int
-	Host string
+	Port int    `mapstructure:"port" default:"8080"`
+	Host string `mapstructure:"host"`
+
+	// ReadTimeout, WriteTimeout and IdleTimeout are set on the underlying
+	// http.Server unless it already has them set, zero means no timeout
+	ReadTimeout  time.Duration `mapstructure:"read_timeout" default:"10s"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout" default:"10s"`
+	IdleTimeout  time.Duration `mapstructure:"idle_timeout" default:"60s"`
+
+	// ShutdownTimeout is how long Run waits for in flight requests to
+	// complete before killing the server
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"10s"`
+}
+
+// setHTTPTimeouts sets the configured timeouts on the http server if
+// they are not already set on it
+func (c Config) setHTTPTimeouts(httpServer *http.Server) {
+	if httpServer.ReadTimeout == 0 {
+		httpServer.ReadTimeout = c.ReadTimeout
+	}
+	if httpServer.WriteTimeout == 0 {
+		httpServer.WriteTimeout = c.WriteTimeout
+	}
+	if httpServer.IdleTimeout == 0 {
+		httpServer.IdleTimeout = c.IdleTimeout
+	}
 }
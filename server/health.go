@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ReadinessCheck reports an error if the service is not ready to serve
+// requests e.g. when a database it depends on is not reachable
+type ReadinessCheck func(ctx context.Context) error
+
+// RegisterHealthHandlers registers a liveness endpoint on /healthz which
+// always responds with 200 and a readiness endpoint on /readyz which
+// responds with 200 if all the checks pass, 503 with the error otherwise
+func (s *HTTPServer) RegisterHealthHandlers(checks ...ReadinessCheck) {
+	s.RegisterHandler("/healthz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	s.RegisterHandler("/readyz", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, check := range checks {
+			if err := check(r.Context()); err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		fmt.Fprint(w, "ok")
+	}))
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newHealthServer(t *testing.T, checks ...ReadinessCheck) *httptest.Server {
+	t.Helper()
+	s, err := NewHTTP(Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.RegisterHealthHandlers(checks...)
+
+	ts := httptest.NewServer(s.httpMux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func get(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestHealthHandlers(t *testing.T) {
+	ready := func(ctx context.Context) error { return nil }
+	notReady := func(ctx context.Context) error { return errors.New("database is not reachable") }
+
+	t.Run("should respond ok on healthz even if not ready", func(t *testing.T) {
+		ts := newHealthServer(t, notReady)
+
+		status, body := get(t, ts.URL+"/healthz")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "ok", body)
+	})
+
+	t.Run("should respond ok on readyz if all checks pass", func(t *testing.T) {
+		ts := newHealthServer(t, ready, ready)
+
+		status, body := get(t, ts.URL+"/readyz")
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, "ok", body)
+	})
+
+	t.Run("should respond 503 on readyz with the error of a failing check", func(t *testing.T) {
+		ts := newHealthServer(t, ready, notReady)
+
+		status, body := get(t, ts.URL+"/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, status)
+		assert.Equal(t, "database is not reachable\n", body)
+	})
+
+	t.Run("should pass the request context to checks", func(t *testing.T) {
+		var checked bool
+		ts := newHealthServer(t, func(ctx context.Context) error {
+			checked = ctx != nil && ctx.Err() == nil
+			return nil
+		})
+
+		status, _ := get(t, ts.URL+"/readyz")
+		assert.Equal(t, http.StatusOK, status)
+		assert.True(t, checked)
+	})
+}
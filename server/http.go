@@ -43,6 +43,7 @@ func NewHTTP(config Config, options ...HTTPOption) (*HTTPServer, error) {
 		server.httpServer = &http.Server{}
 	}
 	server.httpServer.Addr = fmt.Sprintf("%s:%d", config.Host, config.Port)
+	config.setHTTPTimeouts(server.httpServer)
 	server.httpMux = http.NewServeMux()
 
 	return server, nil
@@ -66,6 +66,7 @@ func NewMux(config Config, options ...MuxOption) (*MuxServer, error) {
 	} else {
 		server.httpServer = &http.Server{}
 	}
+	config.setHTTPTimeouts(server.httpServer)
 
 	server.httpMux = http.NewServeMux()
 
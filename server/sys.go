@@ -2,9 +2,12 @@ package server
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 // HandleSignals wraps context so that it is marked done
@@ -17,3 +20,36 @@ func HandleSignals(ctx context.Context) context.Context {
 	}()
 	return newCtx
 }
+
+// Server can serve requests until it is shut down
+type Server interface {
+	Serve() error
+	Shutdown(ctx context.Context)
+}
+
+// Run serves requests until ctx is done or one of SIGINT or SIGTERM is
+// received, then shuts the server down gracefully, killing it if the
+// requests in flight take longer than shutdownTimeout to complete.
+// It returns the error if serving fails.
+func Run(ctx context.Context, s Server, shutdownTimeout time.Duration) error {
+	ctx = HandleSignals(ctx)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Serve()
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	s.Shutdown(shutdownCtx)
+	return nil
+}
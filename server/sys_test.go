@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/odpf/salt/server"
+)
+
+// fakeServer serves until it is shut down, or returns serveErr at once
+// if set
+type fakeServer struct {
+	serveErr    error
+	closed      chan struct{}
+	shutdownCtx chan context.Context
+}
+
+func newFakeServer(serveErr error) *fakeServer {
+	return &fakeServer{
+		serveErr:    serveErr,
+		closed:      make(chan struct{}),
+		shutdownCtx: make(chan context.Context, 1),
+	}
+}
+
+func (s *fakeServer) Serve() error {
+	if s.serveErr != nil {
+		return s.serveErr
+	}
+	<-s.closed
+	return http.ErrServerClosed
+}
+
+func (s *fakeServer) Shutdown(ctx context.Context) {
+	s.shutdownCtx <- ctx
+	close(s.closed)
+}
+
+func TestRun(t *testing.T) {
+	t.Run("should shut down and return when ctx is cancelled", func(t *testing.T) {
+		s := newFakeServer(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		done := make(chan error, 1)
+		go func() {
+			done <- server.Run(ctx, s, time.Minute)
+		}()
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after ctx was cancelled")
+		}
+
+		shutdownCtx := <-s.shutdownCtx
+		deadline, ok := shutdownCtx.Deadline()
+		assert.True(t, ok)
+		assert.True(t, time.Until(deadline) <= time.Minute)
+	})
+
+	t.Run("should return the error if serving fails", func(t *testing.T) {
+		s := newFakeServer(errors.New("address already in use"))
+
+		err := server.Run(context.Background(), s, time.Minute)
+		assert.EqualError(t, err, "address already in use")
+		assert.Len(t, s.shutdownCtx, 0)
+	})
+
+	t.Run("should return nil if the server is closed", func(t *testing.T) {
+		s := newFakeServer(http.ErrServerClosed)
+
+		assert.NoError(t, server.Run(context.Background(), s, time.Minute))
+	})
+}